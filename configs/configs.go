@@ -4,6 +4,7 @@ package configs
 import (
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golangplus/strings"
@@ -34,6 +35,17 @@ var (
 
 	LoadTemplatePass = ""
 	AutoLoadTemplate = false
+	// The scorer of matching, "default" or "bm25". It can be overridden by
+	// the "scorer" parameter of a search.
+	SearchScorer = "default"
+	// The file of synonym groups expanding query tokens, e.g. a line of
+	// "k8s, kubernetes". No expansion if empty.
+	SynonymsPath = ""
+	// The address serving the gRPC SearchService. Not served if empty.
+	SearchGrpcAddr = ""
+	// The URL of the site, prefixed to the absolute URLs of the sitemaps,
+	// feeds, badges and the OpenSearch description.
+	SiteURL = "http://go-search.org"
 
 	DataRoot = villa.Path("./data/")
 
@@ -73,6 +85,10 @@ func init() {
 	ServerRoot = conf.Path("web.root", ServerRoot)
 	LoadTemplatePass = conf.String("web.loadtemplatepass", LoadTemplatePass)
 	AutoLoadTemplate = conf.Bool("web.autoloadtemplate", AutoLoadTemplate)
+	SearchScorer = conf.String("web.scorer", SearchScorer)
+	SynonymsPath = conf.String("web.synonyms", SynonymsPath)
+	SearchGrpcAddr = conf.String("web.grpcaddr", SearchGrpcAddr)
+	SiteURL = strings.TrimSuffix(conf.String("web.siteurl", SiteURL), "/")
 
 	DataRoot = conf.Path("back.dbroot", DataRoot)
 
@@ -135,6 +151,12 @@ func IndexPath() villa.Path {
 	return DataRoot.Join("index")
 }
 
+// WebhooksPath returns the JSON file of the webhook subscriptions. Producer:
+// server, consumers: mergedocs and indexer.
+func WebhooksPath() string {
+	return DataRoot.Join("webhooks.json").S()
+}
+
 func StoreBoltPath() string {
 	return DataRoot.Join("store.bolt").S()
 }
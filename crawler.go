@@ -144,6 +144,15 @@ func HostOfPackage(pkg string) string {
 	return u.Host
 }
 
+// UpstreamPackage returns the package in the project forkOf corresponding to
+// pkg in a fork of it, or pkg if forkOf is empty.
+func UpstreamPackage(pkg, forkOf string) string {
+	if forkOf == "" {
+		return pkg
+	}
+	return forkOf + strings.TrimPrefix(pkg, FullProjectOfPackage(pkg))
+}
+
 func FullProjectOfPackage(pkg string) string {
 	parts := strings.Split(pkg, "/")
 	if len(parts) == 0 {
@@ -190,7 +199,10 @@ type Package struct {
 	ReadmeData  string
 	Imports     []string
 	TestImports []string
-	Exported    []string // exported tokens(funcs/types)
+	Exported    []string // exported tokens(funcs/types/Type.Method)
+	// ForkOf is the project the repository is forked from, e.g.
+	// "github.com/golang/go", or empty if not a fork.
+	ForkOf string
 
 	References []string
 	Etag       string
@@ -383,26 +395,33 @@ func CrawlRepoInfo(ctx context.Context, site, user, name string) *gpb.RepoInfo {
 	return ri
 }
 
-func getGithubStars(ctx context.Context, user, name string) int {
+// getGithubRepo returns the stars, or -1 if unknown, and the project the repo
+// is forked from, if any.
+func getGithubRepo(ctx context.Context, user, name string) (stars int, forkOf string) {
 	r := CrawlRepoInfo(ctx, "github.com", user, name)
-	if r != nil {
-		return int(r.Stars)
+	if r == nil {
+		return -1, ""
+	}
+	// Sources crawled before were only repository names.
+	if strings.HasPrefix(r.Source, "github.com/") {
+		forkOf = r.Source
 	}
-	return -1
+	return int(r.Stars), forkOf
 }
 
-func getGithub(ctx context.Context, pkg string) (*doc.Package, []*gpb.FolderInfo, error) {
+func getGithub(ctx context.Context, pkg string) (*doc.Package, string, []*gpb.FolderInfo, error) {
 	parts := strings.SplitN(pkg, "/", 4)
 	for len(parts) < 4 {
 		parts = append(parts, "")
 	}
 	if parts[1] == "" || parts[2] == "" {
-		return nil, nil, errorsp.WithStacks(ErrInvalidPackage)
+		return nil, "", nil, errorsp.WithStacks(ErrInvalidPackage)
 	}
 	p, folders, err := GithubSpider.ReadPackage(ctx, parts[1], parts[2], parts[3])
 	if err != nil {
-		return nil, folders, err
+		return nil, "", folders, err
 	}
+	stars, forkOf := getGithubRepo(ctx, parts[1], parts[2])
 	return &doc.Package{
 		ImportPath:  pkg,
 		ProjectRoot: strings.Join(parts[:3], "/"),
@@ -414,10 +433,10 @@ func getGithub(ctx context.Context, pkg string) (*doc.Package, []*gpb.FolderInfo
 
 		Imports:     p.Imports,
 		TestImports: p.TestImports,
-		StarCount:   getGithubStars(ctx, parts[1], parts[2]),
+		StarCount:   stars,
 
 		ReadmeFiles: map[string][]byte{p.ReadmeFn: []byte(p.ReadmeData)},
-	}, folders, nil
+	}, forkOf, folders, nil
 }
 
 func CrawlPackage(ctx context.Context, httpClient doc.HttpClient, pkg string, etag string) (p *Package, folders []*gpb.FolderInfo, err error) {
@@ -427,13 +446,14 @@ func CrawlPackage(ctx context.Context, httpClient doc.HttpClient, pkg string, et
 		}
 	}()
 	var pdoc *doc.Package
+	var forkOf string
 
 	if strings.Contains(pkg, "/vendor/") || strings.HasPrefix(pkg, "thezombie.net") {
 		return nil, folders, ErrInvalidPackage
 	}
 	if strings.HasPrefix(pkg, "github.com/") {
 		if GithubSpider != nil {
-			pdoc, folders, err = getGithub(ctx, pkg)
+			pdoc, forkOf, folders, err = getGithub(ctx, pkg)
 		} else {
 			pdoc, err = doc.Get(httpClient, pkg, etag)
 		}
@@ -489,6 +509,12 @@ func CrawlPackage(ctx context.Context, httpClient doc.HttpClient, pkg string, et
 	}
 	for _, t := range pdoc.Types {
 		exported.Add(t.Name)
+		for _, f := range t.Funcs {
+			exported.Add(f.Name)
+		}
+		for _, m := range t.Methods {
+			exported.Add(t.Name + "." + m.Name)
+		}
 	}
 	return &Package{
 		Package:    pdoc.ImportPath,
@@ -504,6 +530,7 @@ func CrawlPackage(ctx context.Context, httpClient doc.HttpClient, pkg string, et
 		Imports:     imports,
 		TestImports: testImports.Elements(),
 		Exported:    exported.Elements(),
+		ForkOf:      forkOf,
 
 		References: pdoc.References,
 		Etag:       pdoc.Etag,
@@ -182,3 +182,9 @@ func TestFullProjectOfPackage(t *testing.T) {
 		assert.Equal(t, "FullProjectOfPackage "+pkg, FullProjectOfPackage(pkg), prj)
 	}
 }
+
+func TestUpstreamPackage(t *testing.T) {
+	assert.Equal(t, "not a fork", UpstreamPackage("github.com/daviddengcn/gcse/index", ""), "github.com/daviddengcn/gcse/index")
+	assert.Equal(t, "project", UpstreamPackage("github.com/someone/gcse", "github.com/daviddengcn/gcse"), "github.com/daviddengcn/gcse")
+	assert.Equal(t, "sub package", UpstreamPackage("github.com/someone/gcse/index", "github.com/daviddengcn/gcse"), "github.com/daviddengcn/gcse/index")
+}
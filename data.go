@@ -3,9 +3,11 @@ package gcse
 import (
 	"encoding/gob"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/golangplus/bytes"
 	"github.com/golangplus/errors"
@@ -30,7 +32,8 @@ type DocInfo struct {
 	ReadmeData  string
 	Imports     []string
 	TestImports []string
-	Exported    []string // exported tokens(funcs/types)
+	Exported    []string // exported tokens(funcs/types/Type.Method)
+	ForkOf      string   // the project forked from, see Package.ForkOf
 }
 
 // Returns a new instance of DocInfo as a sophie.Sophier
@@ -64,6 +67,14 @@ type HitInfo struct {
 	StaticScore       float64
 	TestStaticScore   float64
 	StaticRank        int // zero-based
+
+	// PageRank is the PageRank over the import graph, normalized by
+	// NormalizePageRanks.
+	PageRank float64
+
+	// TextStats are the statistics of the IndexTextField, used by BM25F.
+	// Only the full hit has the Freqs.
+	TextStats FieldStats
 }
 
 func init() {
@@ -107,12 +118,24 @@ var stopWords = stringsp.NewSet(
 	"the", "on", "in", "as",
 )
 
+// IsCJK returns true if r is a Chinese, Japanese or Korean character. Such
+// languages are not separated by spaces, so each character is a token and
+// adjacent characters form bigrams.
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+func isCJKToken(token string) bool {
+	r, _ := utf8.DecodeRuneInString(token)
+	return IsCJK(r)
+}
+
 func CheckRuneType(last, current rune) index.RuneType {
 	if isTermSep(current) {
 		return index.TokenSep
 	}
 
-	if current > 128 {
+	if IsCJK(current) || IsCJK(last) {
 		return index.TokenStart
 	}
 
@@ -163,6 +186,15 @@ func CheckCamel(last, current rune) index.RuneType {
 
 // a block does not contain blanks
 func appendTokensOfBlock(tokens stringsp.Set, block []byte) stringsp.Set {
+	forEachTokenOfBlock(block, NormWord, func(token string) {
+		tokens.Add(token)
+	})
+	return tokens
+}
+
+// forEachTokenOfBlock calls out with every token, normalized by norm, of
+// block, including duplicates.
+func forEachTokenOfBlock(block []byte, norm func(string) string, out func(token string)) {
 	lastToken := ""
 	index.Tokenize(CheckRuneType, (*bytesp.Slice)(&block),
 		func(token []byte) error {
@@ -172,46 +204,151 @@ func appendTokensOfBlock(tokens stringsp.Set, block []byte) stringsp.Set {
 				index.Tokenize(CheckCamel, bytesp.NewPSlice(token),
 					func(token []byte) error {
 						tokenStr := string(token)
-						tokenStr = NormWord(tokenStr)
+						tokenStr = norm(tokenStr)
 						if !stopWords.Contain(tokenStr) {
-							tokens.Add(tokenStr)
+							out(tokenStr)
 						}
 						if last != "" {
-							tokens.Add(last + string(tokenStr))
+							out(last + string(tokenStr))
 						}
 						last = tokenStr
 						return nil
 					})
 			}
-			tokenStr = NormWord(tokenStr)
+			tokenStr = norm(tokenStr)
 			if !stopWords.Contain(tokenStr) {
-				tokens.Add(tokenStr)
+				out(tokenStr)
 			}
 			if lastToken != "" {
-				if tokenStr[0] > 128 && lastToken[0] > 128 {
-					// Chinese bigrams
-					tokens.Add(lastToken + tokenStr)
-				} else if tokenStr[0] <= 128 && lastToken[0] <= 128 {
-					tokens.Add(lastToken + "-" + tokenStr)
+				cjk, lastCJK := isCJKToken(tokenStr), isCJKToken(lastToken)
+				if cjk && lastCJK {
+					// CJK bigrams
+					out(lastToken + tokenStr)
+				} else if !cjk && !lastCJK {
+					out(lastToken + "-" + tokenStr)
 				}
 			}
 			lastToken = tokenStr
 			return nil
 		})
-	return tokens
 }
 
-// Tokenizes text into the current token set.
-func AppendTokens(tokens stringsp.Set, text []byte) stringsp.Set {
+// forEachToken calls out with every token, normalized by norm, of text,
+// including duplicates.
+func forEachToken(text []byte, norm func(string) string, out func(token string)) {
 	textBuf := filterURLs(text)
 	textBuf = filterEmails(textBuf)
 
 	index.Tokenize(index.SeparatorFRuneTypeFunc(unicode.IsSpace),
 		(*bytesp.Slice)(&textBuf), func(block []byte) error {
-			tokens = appendTokensOfBlock(tokens, block)
+			forEachTokenOfBlock(block, norm, out)
 			return nil
 		})
+}
+
+// Tokenizes text into the current token set.
+func AppendTokens(tokens stringsp.Set, text []byte) stringsp.Set {
+	forEachToken(text, NormWord, func(token string) {
+		tokens.Add(token)
+	})
+	return tokens
+}
 
+// AppendRawTokens is the same as AppendTokens except that tokens are only
+// lower-cased but not stemmed.
+func AppendRawTokens(tokens stringsp.Set, text []byte) stringsp.Set {
+	forEachToken(text, strings.ToLower, func(token string) {
+		tokens.Add(token)
+	})
+	return tokens
+}
+
+// AppendSymbolTokens adds the tokens of an exported identifier, which are
+// case-preserved. A method like "Router.ServeHTTP" is also found by its name.
+func AppendSymbolTokens(tokens stringsp.Set, sym string) stringsp.Set {
+	if sym == "" {
+		return tokens
+	}
+	tokens.Add(sym)
+	if p := strings.LastIndex(sym, "."); p >= 0 && p < len(sym)-1 {
+		tokens.Add(sym[p+1:])
+	}
+	return tokens
+}
+
+// CountTokens adds the frequencies of the tokens of text to counts, which is
+// allocated if nil, and returns it together with the number of tokens.
+func CountTokens(counts map[string]int, text []byte) (map[string]int, int) {
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+	n := 0
+	forEachToken(text, NormWord, func(token string) {
+		counts[token]++
+		n++
+	})
+	return counts, n
+}
+
+// FieldStats are the token statistics of an indexed field of a package.
+type FieldStats struct {
+	// Len is the number of tokens, counting duplicates.
+	Len int
+	// Freqs are the frequencies larger than 1. Other tokens of the field
+	// appear once.
+	Freqs map[string]int
+}
+
+// NewFieldStats returns the statistics of a field consisting of texts.
+func NewFieldStats(texts ...string) FieldStats {
+	var counts map[string]int
+	var stats FieldStats
+	for _, text := range texts {
+		var n int
+		counts, n = CountTokens(counts, []byte(text))
+		stats.Len += n
+	}
+	for token, freq := range counts {
+		if freq > 1 {
+			if stats.Freqs == nil {
+				stats.Freqs = make(map[string]int)
+			}
+			stats.Freqs[token] = freq
+		}
+	}
+	return stats
+}
+
+// Freq returns the frequency of a token known to be in the field.
+func (s FieldStats) Freq(token string) int {
+	if freq := s.Freqs[token]; freq > 1 {
+		return freq
+	}
+	return 1
+}
+
+// FreqBuckets are the frequencies of FreqToken, the highest first. Higher
+// frequencies are rounded down to the first one.
+var FreqBuckets = []int{64, 32, 16, 8, 4, 2}
+
+// FreqToken returns the token of IndexTextFreqField of token appearing at
+// least bucket, one of FreqBuckets, times.
+func FreqToken(token string, bucket int) string {
+	return token + "#" + strconv.Itoa(bucket)
+}
+
+// FreqTokens returns the FreqTokens of the frequencies larger than 1, each
+// rounded down to a bucket.
+func (s FieldStats) FreqTokens() stringsp.Set {
+	var tokens stringsp.Set
+	for token, freq := range s.Freqs {
+		for _, bucket := range FreqBuckets {
+			if freq >= bucket {
+				tokens.Add(FreqToken(token, bucket))
+				break
+			}
+		}
+	}
 	return tokens
 }
 
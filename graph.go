@@ -0,0 +1,217 @@
+package gcse
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/golangplus/sort"
+	"github.com/golangplus/strings"
+)
+
+// Formats of WriteImportGraph.
+const (
+	GraphFormatDOT     = "dot"
+	GraphFormatGraphML = "graphml"
+	GraphFormatJSON    = "json"
+)
+
+// ImportEdge is an edge of an ImportGraph, From importing To.
+type ImportEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Test is set if To is only imported in tests.
+	Test bool `json:"test,omitempty"`
+}
+
+// ImportGraph is the import graph of packages, or of projects if Projects is
+// set.
+type ImportGraph struct {
+	// Tests is whether test imports are added.
+	Tests bool
+	// Projects is whether packages are collapsed to their projects, returned
+	// by FullProjectOfPackage. Imports inside a project are dropped.
+	Projects bool
+
+	nodes map[string]bool
+	// edges maps an edge to whether it is a test-only import.
+	edges map[[2]string]bool
+}
+
+func (g *ImportGraph) node(pkg string) string {
+	if g.Projects {
+		if proj := FullProjectOfPackage(pkg); proj != "" {
+			pkg = proj
+		}
+	}
+	if g.nodes == nil {
+		g.nodes = make(map[string]bool)
+	}
+	g.nodes[pkg] = true
+	return pkg
+}
+
+func (g *ImportGraph) addEdge(from, to string, test bool) {
+	to = g.node(to)
+	if from == to {
+		return
+	}
+	if g.edges == nil {
+		g.edges = make(map[[2]string]bool)
+	}
+	e := [2]string{from, to}
+	if isTest, ok := g.edges[e]; !ok || isTest && !test {
+		g.edges[e] = test
+	}
+}
+
+// AddPackage adds a package and its imports to the graph.
+func (g *ImportGraph) AddPackage(pkg string, imports, testImports []string) {
+	from := g.node(pkg)
+	for _, imp := range imports {
+		g.addEdge(from, imp, false)
+	}
+	if !g.Tests {
+		return
+	}
+	for _, imp := range testImports {
+		g.addEdge(from, imp, true)
+	}
+}
+
+// AddImportClosure adds roots and the packages they import, directly or not,
+// to the graph. Test imports are only followed from roots. find returns the
+// imports of a package, or false if it is not found, in which case it is kept
+// as a leaf.
+func (g *ImportGraph) AddImportClosure(roots []string, find func(pkg string) (imports, testImports []string, found bool)) {
+	isRoot := stringsp.NewSet(roots...)
+	visited := stringsp.NewSet()
+	var queue []string
+	visit := func(pkgs []string) {
+		for _, pkg := range pkgs {
+			if !visited.Contain(pkg) {
+				visited.Add(pkg)
+				queue = append(queue, pkg)
+			}
+		}
+	}
+	visit(roots)
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		imports, testImports, found := find(pkg)
+		if !found {
+			g.node(pkg)
+			continue
+		}
+		if !g.Tests || !isRoot.Contain(pkg) {
+			testImports = nil
+		}
+		g.AddPackage(pkg, imports, testImports)
+		visit(imports)
+		visit(testImports)
+	}
+}
+
+// Nodes returns the sorted packages, or projects, of the graph.
+func (g *ImportGraph) Nodes() []string {
+	nodes := make([]string, 0, len(g.nodes))
+	for node := range g.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Edges returns the edges sorted by From and To.
+func (g *ImportGraph) Edges() []ImportEdge {
+	edges := make([]ImportEdge, 0, len(g.edges))
+	for e, test := range g.edges {
+		edges = append(edges, ImportEdge{From: e[0], To: e[1], Test: test})
+	}
+	sortp.SortF(len(edges), func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	}, func(i, j int) {
+		edges[i], edges[j] = edges[j], edges[i]
+	})
+	return edges
+}
+
+// WriteImportGraph writes g to w in format, one of GraphFormatDOT,
+// GraphFormatGraphML and GraphFormatJSON. Test-only imports are dashed in DOT.
+func WriteImportGraph(w io.Writer, g *ImportGraph, format string) error {
+	switch format {
+	case GraphFormatDOT:
+		return writeGraphDOT(w, g)
+	case GraphFormatGraphML:
+		return writeGraphML(w, g)
+	case GraphFormatJSON:
+		return json.NewEncoder(w).Encode(struct {
+			Nodes []string     `json:"nodes"`
+			Edges []ImportEdge `json:"edges"`
+		}{g.Nodes(), g.Edges()})
+	}
+	return fmt.Errorf("unknown graph format: %q", format)
+}
+
+func writeGraphDOT(w io.Writer, g *ImportGraph) error {
+	if _, err := fmt.Fprintln(w, "digraph imports {"); err != nil {
+		return err
+	}
+	for _, node := range g.Nodes() {
+		if _, err := fmt.Fprintf(w, "\t%s;\n", strconv.Quote(node)); err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges() {
+		attrs := ""
+		if e.Test {
+			attrs = " [style=dashed]"
+		}
+		if _, err := fmt.Fprintf(w, "\t%s -> %s%s;\n", strconv.Quote(e.From), strconv.Quote(e.To), attrs); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+func writeGraphML(w io.Writer, g *ImportGraph) error {
+	if _, err := fmt.Fprint(w, xml.Header+`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="test" for="edge" attr.name="test" attr.type="boolean">
+    <default>false</default>
+  </key>
+  <graph id="imports" edgedefault="directed">
+`); err != nil {
+		return err
+	}
+	for _, node := range g.Nodes() {
+		if _, err := fmt.Fprintf(w, "    <node id=\"%s\"/>\n", xmlEscape(node)); err != nil {
+			return err
+		}
+	}
+	for _, e := range g.Edges() {
+		data := ""
+		if e.Test {
+			data = `<data key="test">true</data>`
+		}
+		if _, err := fmt.Fprintf(w, "    <edge source=\"%s\" target=\"%s\">%s</edge>\n", xmlEscape(e.From), xmlEscape(e.To), data); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprint(w, "  </graph>\n</graphml>\n")
+	return err
+}
@@ -0,0 +1,76 @@
+package gcse
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+)
+
+func TestImportGraph(t *testing.T) {
+	g := &ImportGraph{Tests: true}
+	g.AddPackage("github.com/a/x", []string{"fmt", "github.com/a/x/y"}, []string{"testing", "fmt"})
+	assert.Equal(t, "Nodes", g.Nodes(), []string{"fmt", "github.com/a/x", "github.com/a/x/y", "testing"})
+	assert.Equal(t, "Edges", g.Edges(), []ImportEdge{
+		{From: "github.com/a/x", To: "fmt"},
+		{From: "github.com/a/x", To: "github.com/a/x/y"},
+		{From: "github.com/a/x", To: "testing", Test: true},
+	})
+
+	g = &ImportGraph{Projects: true}
+	g.AddPackage("github.com/a/x", []string{"fmt", "github.com/a/x/y"}, []string{"testing"})
+	g.AddPackage("github.com/a/x/y", []string{"github.com/b/z/w"}, nil)
+	assert.Equal(t, "Nodes", g.Nodes(), []string{"fmt", "github.com/a/x", "github.com/b/z"})
+	assert.Equal(t, "Edges", g.Edges(), []ImportEdge{
+		{From: "github.com/a/x", To: "fmt"},
+		{From: "github.com/a/x", To: "github.com/b/z"},
+	})
+}
+
+func TestImportGraph_AddImportClosure(t *testing.T) {
+	deps := map[string][2][]string{
+		"a": {{"b"}, {"t"}},
+		"b": {{"c"}, {"u"}},
+		"c": {{"a"}, nil},
+	}
+	find := func(pkg string) (imports, testImports []string, found bool) {
+		d, found := deps[pkg]
+		return d[0], d[1], found
+	}
+	g := &ImportGraph{Tests: true}
+	g.AddImportClosure([]string{"a"}, find)
+	// Test imports of b are not followed, and the cycle stops.
+	assert.Equal(t, "Nodes", g.Nodes(), []string{"a", "b", "c", "t"})
+	assert.Equal(t, "Edges", g.Edges(), []ImportEdge{
+		{From: "a", To: "b"},
+		{From: "a", To: "t", Test: true},
+		{From: "b", To: "c"},
+		{From: "c", To: "a"},
+	})
+}
+
+func TestWriteImportGraph(t *testing.T) {
+	g := &ImportGraph{Tests: true}
+	g.AddPackage("a", []string{"b"}, []string{"c&d"})
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteImportGraph(&buf, g, GraphFormatDOT))
+	assert.Equal(t, "dot", buf.String(), `digraph imports {
+	"a";
+	"b";
+	"c&d";
+	"a" -> "b";
+	"a" -> "c&d" [style=dashed];
+}
+`)
+
+	buf.Reset()
+	assert.NoError(t, WriteImportGraph(&buf, g, GraphFormatJSON))
+	assert.Equal(t, "json", buf.String(), `{"nodes":["a","b","c\u0026d"],"edges":[{"from":"a","to":"b"},{"from":"a","to":"c\u0026d","test":true}]}`+"\n")
+
+	buf.Reset()
+	assert.NoError(t, WriteImportGraph(&buf, g, GraphFormatGraphML))
+	assert.True(t, "graphml", bytes.Contains(buf.Bytes(), []byte(`<edge source="a" target="c&amp;d"><data key="test">true</data></edge>`)))
+
+	assert.Error(t, WriteImportGraph(&buf, g, "png"))
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"log"
+	"math"
 	"os"
 	"path"
 	"time"
@@ -26,6 +27,20 @@ const (
 	IndexTextField = "text"
 	IndexNameField = "name"
 	IndexPkgField  = "pkg"
+	// IndexRawField has the unstemmed tokens of the IndexTextField, for
+	// ranking exact matches higher.
+	IndexRawField = "raw"
+	// IndexSymbolField has the exported identifiers, e.g. "NewRouter" and
+	// "Router.ServeHTTP", case-preserved.
+	IndexSymbolField = "sym"
+	// IndexTextFreqField has the FreqTokens of the IndexTextField, for the
+	// term frequencies of BM25F.
+	IndexTextFreqField = "textfreq"
+	// IndexImportsField has the import paths of the imports, for finding the
+	// importers of a package in memory.
+	IndexImportsField = "imports"
+	// IndexTestImportsField has the import paths of the test imports.
+	IndexTestImportsField = "testimports"
 )
 
 var errNotDocInfo = errors.New("Value is not DocInfo")
@@ -74,6 +89,8 @@ func indexAndSaveHits(ts *index.TokenSetSearcher, hits []HitInfo, idxs []int, sa
 			rank = i
 		}
 		hit.StaticRank = rank
+		// The tokens of the IndexTextField below.
+		hit.TextStats = NewFieldStats(append([]string{hit.Name, hit.Package, hit.Description, hit.ReadmeData, hit.Author}, hit.Exported...)...)
 
 		if err := saveFullHit(hit); err != nil {
 			return err
@@ -84,6 +101,9 @@ func indexAndSaveHits(ts *index.TokenSetSearcher, hits []HitInfo, idxs []int, sa
 		readme, hit.ReadmeData = hit.ReadmeData, ""
 		hit.Imported = nil
 		hit.TestImported = nil
+		// The frequencies are in the IndexTextFreqField.
+		freqTokens := hit.TextStats.FreqTokens()
+		hit.TextStats.Freqs = nil
 
 		var nameTokens stringsp.Set
 		nameTokens = AppendTokens(nameTokens, []byte(hit.Name))
@@ -94,13 +114,25 @@ func indexAndSaveHits(ts *index.TokenSetSearcher, hits []HitInfo, idxs []int, sa
 		tokens = AppendTokens(tokens, []byte(desc))
 		tokens = AppendTokens(tokens, []byte(readme))
 		tokens = AppendTokens(tokens, []byte(hit.Author))
+		var symTokens stringsp.Set
 		for _, word := range hit.Exported {
-			AppendTokens(tokens, []byte(word))
+			tokens = AppendTokens(tokens, []byte(word))
+			symTokens = AppendSymbolTokens(symTokens, word)
+		}
+
+		var rawTokens stringsp.Set
+		for _, text := range []string{hit.Name, hit.Package, desc, readme, hit.Author} {
+			rawTokens = AppendRawTokens(rawTokens, []byte(text))
 		}
 		ts.AddDoc(map[string]stringsp.Set{
-			IndexTextField: tokens,
-			IndexNameField: nameTokens,
-			IndexPkgField:  stringsp.NewSet(hit.Package),
+			IndexTextField:        tokens,
+			IndexNameField:        nameTokens,
+			IndexPkgField:         stringsp.NewSet(hit.Package),
+			IndexRawField:         rawTokens,
+			IndexSymbolField:      symTokens,
+			IndexTextFreqField:    freqTokens,
+			IndexImportsField:     stringsp.NewSet(hit.Imports...),
+			IndexTestImportsField: stringsp.NewSet(hit.TestImports...),
 		}, *hit)
 		if bar != nil {
 			bar.Increment()
@@ -132,6 +164,7 @@ func Index(docDB mr.Input, outDir string) (*index.TokenSetSearcher, error) {
 		LastUpdated time.Time
 	}
 	prjStars := make(map[string]projectStart)
+	var pkgs []string
 
 	// generate importsDB
 	for i := 0; i < docPartCnt; i++ {
@@ -151,6 +184,7 @@ func Index(docDB mr.Input, outDir string) (*index.TokenSetSearcher, error) {
 			}
 			filterDocInfo(&docInfo)
 
+			pkgs = append(pkgs, string(pkg))
 			importsDB.PutTokens(string(pkg), stringsp.NewSet(docInfo.Imports...))
 			testImportsDB.PutTokens(string(pkg), stringsp.NewSet(docInfo.TestImports...))
 
@@ -179,6 +213,15 @@ func Index(docDB mr.Input, outDir string) (*index.TokenSetSearcher, error) {
 		it.Close()
 	}
 
+	log.Printf("Calculating PageRanks of %d packages ...", len(pkgs))
+	pageRanks := ImportPageRanks(pkgs, importsDB.TokensOfId)
+	maxImported := 0.
+	for _, pkg := range pkgs {
+		maxImported = math.Max(maxImported, effectiveImported(importsDB.IdsOfToken(pkg), AuthorOfPackage(pkg), ProjectOfPackage(pkg)))
+	}
+	NormalizePageRanks(pageRanks, maxImported)
+	pkgs = nil
+
 	utils.DumpMemStats()
 	log.Printf("Making HitInfos ...")
 	hits := make([]HitInfo, 0, docCount)
@@ -226,6 +269,7 @@ func Index(docDB mr.Input, outDir string) (*index.TokenSetSearcher, error) {
 				}
 			}
 			hitInfo.AssignedStarCount = assignedStarCount
+			hitInfo.PageRank = pageRanks[hitInfo.Package]
 
 			readme := ReadmeToText(hitInfo.ReadmeFn, hitInfo.ReadmeData)
 
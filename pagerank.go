@@ -0,0 +1,124 @@
+package gcse
+
+import (
+	"math"
+)
+
+const (
+	pageRankDamping = 0.85
+	// The iterations stop after this or when the total change of the ranks
+	// is less than pageRankEpsilon per package.
+	maxPageRankIterations = 100
+	pageRankEpsilon       = 1e-6
+	// The weight of an import from a package of the same author or project,
+	// like the discount of effectiveImported.
+	sameAuthorImportWeight = 0.5
+)
+
+// PageRanks returns the PageRanks of n nodes where node i links to out[i],
+// with the weights in weights[i], or of weight 1 if weights[i] is nil. A node
+// passes its rank to the nodes it links to in proportion to the weights, and
+// the ranks of nodes without links are spread evenly. The ranks are scaled to
+// sum up to n, so 1 is the average rank.
+func PageRanks(out [][]int, weights [][]float64) []float64 {
+	n := len(out)
+	if n == 0 {
+		return nil
+	}
+	weight := func(i, k int) float64 {
+		if i >= len(weights) || weights[i] == nil {
+			return 1
+		}
+		return weights[i][k]
+	}
+	sums := make([]float64, n)
+	for i, js := range out {
+		for k := range js {
+			sums[i] += weight(i, k)
+		}
+	}
+	ranks, next := make([]float64, n), make([]float64, n)
+	for i := range ranks {
+		ranks[i] = 1
+	}
+	for iter := 0; iter < maxPageRankIterations; iter++ {
+		dangling := 0.
+		for i := range next {
+			next[i] = 0
+		}
+		for i, js := range out {
+			if sums[i] <= 0 {
+				dangling += ranks[i]
+				continue
+			}
+			for k, j := range js {
+				next[j] += ranks[i] * weight(i, k) / sums[i]
+			}
+		}
+		base := (1 - pageRankDamping) + pageRankDamping*dangling/float64(n)
+		delta := 0.
+		for i := range next {
+			next[i] = base + pageRankDamping*next[i]
+			delta += math.Abs(next[i] - ranks[i])
+		}
+		ranks, next = next, ranks
+		if delta < pageRankEpsilon*float64(n) {
+			break
+		}
+	}
+	return ranks
+}
+
+// NormalizePageRanks scales ranks linearly from the lowest one, of packages
+// imported by none, to the highest one, to [0, maxImported], the range of
+// effectiveImported. Raw ranks are 1 on average, have a floor of about
+// 1-pageRankDamping and grow with the size of the graph, so they are not
+// comparable with counts of importers otherwise.
+func NormalizePageRanks(ranks map[string]float64, maxImported float64) {
+	minRank, maxRank := math.Inf(1), 0.
+	for _, rank := range ranks {
+		minRank, maxRank = math.Min(minRank, rank), math.Max(maxRank, rank)
+	}
+	if maxRank <= minRank {
+		for pkg := range ranks {
+			ranks[pkg] = 0
+		}
+		return
+	}
+	for pkg, rank := range ranks {
+		ranks[pkg] = (rank - minRank) * maxImported / (maxRank - minRank)
+	}
+}
+
+// ImportPageRanks returns the PageRanks of pkgs over the import graph, where
+// importsOf returns the imports of a package. A package ranks high if it is
+// imported by packages ranking high, not only by many packages. Imports of
+// packages not in pkgs are ignored, and imports from the same author or
+// project are weighted by sameAuthorImportWeight.
+func ImportPageRanks(pkgs []string, importsOf func(pkg string) []string) map[string]float64 {
+	idxs := make(map[string]int, len(pkgs))
+	for i, pkg := range pkgs {
+		idxs[pkg] = i
+	}
+	out, weights := make([][]int, len(pkgs)), make([][]float64, len(pkgs))
+	for i, pkg := range pkgs {
+		author, project := AuthorOfPackage(pkg), ProjectOfPackage(pkg)
+		for _, imp := range importsOf(pkg) {
+			j, ok := idxs[imp]
+			if !ok || j == i {
+				continue
+			}
+			w := 1.
+			if author != "" && AuthorOfPackage(imp) == author || ProjectOfPackage(imp) == project {
+				w = sameAuthorImportWeight
+			}
+			out[i], weights[i] = append(out[i], j), append(weights[i], w)
+		}
+	}
+	ranks := PageRanks(out, weights)
+	res := make(map[string]float64, len(pkgs))
+	for i, pkg := range pkgs {
+		res[pkg] = ranks[i]
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package gcse
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+)
+
+func TestPageRanks(t *testing.T) {
+	// 0 and 1 import 2; 3 imports 4; 2 and 4 import nothing.
+	ranks := PageRanks([][]int{{2}, {2}, nil, {4}, nil}, nil)
+	sum := 0.
+	for _, r := range ranks {
+		sum += r
+	}
+	assert.True(t, "sum", math.Abs(sum-5) < 1e-4)
+	assert.True(t, "2 > 4", ranks[2] > ranks[4])
+	assert.True(t, "4 > 3", ranks[4] > ranks[3])
+	assert.True(t, "0 == 1", math.Abs(ranks[0]-ranks[1]) < 1e-9)
+
+	assert.Equal(t, "empty", PageRanks(nil, nil), []float64(nil))
+}
+
+func TestImportPageRanks(t *testing.T) {
+	// Both a/lib and b/lib are imported once, but a/lib by a popular package.
+	imports := map[string][]string{
+		"x/app1":    {"x/popular"},
+		"y/app2":    {"x/popular"},
+		"z/app3":    {"x/popular"},
+		"x/popular": {"a/lib", "fmt"},
+		"w/tiny":    {"b/lib"},
+	}
+	pkgs := []string{"x/app1", "y/app2", "z/app3", "x/popular", "w/tiny", "a/lib", "b/lib", "fmt"}
+	ranks := ImportPageRanks(pkgs, func(pkg string) []string {
+		return imports[pkg]
+	})
+	assert.Equal(t, "len", len(ranks), len(pkgs))
+	assert.True(t, "a/lib > b/lib", ranks["a/lib"] > ranks["b/lib"])
+	assert.True(t, "x/popular > a/lib", ranks["x/popular"] > ranks["a/lib"])
+
+	hitA := HitInfo{DocInfo: DocInfo{Package: "a/lib"}, Imported: []string{"x/popular"}, PageRank: ranks["a/lib"]}
+	hitB := HitInfo{DocInfo: DocInfo{Package: "b/lib"}, Imported: []string{"w/tiny"}, PageRank: ranks["b/lib"]}
+	assert.True(t, "StaticScore", CalcStaticScore(&hitA) > CalcStaticScore(&hitB))
+}
+
+func TestNormalizePageRanks(t *testing.T) {
+	// A hub imported by many apps, a package imported by the hub only, and a
+	// leaf imported by nothing.
+	imports := map[string][]string{"h/hub": {"m/mid"}}
+	importers := make(map[string][]string)
+	pkgs := []string{"h/hub", "m/mid", "l/leaf"}
+	for i := 0; i < 20; i++ {
+		app := fmt.Sprintf("a%d/app", i)
+		imports[app] = []string{"h/hub"}
+		importers["h/hub"] = append(importers["h/hub"], app)
+		pkgs = append(pkgs, app)
+	}
+	importers["m/mid"] = []string{"h/hub"}
+	ranks := ImportPageRanks(pkgs, func(pkg string) []string {
+		return imports[pkg]
+	})
+	NormalizePageRanks(ranks, 20)
+	for _, rank := range ranks {
+		assert.True(t, "rank in [0, 20]", rank >= 0 && rank <= 20+1e-9)
+	}
+
+	hitOf := func(pkg string) *HitInfo {
+		return &HitInfo{DocInfo: DocInfo{Package: pkg}, Imported: importers[pkg], PageRank: ranks[pkg]}
+	}
+	hub, mid, leaf := hitOf("h/hub"), hitOf("m/mid"), hitOf("l/leaf")
+	assert.True(t, "hub > mid", CalcStaticScore(hub) > CalcStaticScore(mid))
+	assert.True(t, "mid > leaf", CalcStaticScore(mid) > CalcStaticScore(leaf))
+	// Imported by none, the leaf gets nothing from the PageRank.
+	assert.Equal(t, "leaf", importedScore(leaf, "", "l/leaf"), 0.)
+}
@@ -124,6 +124,7 @@ func packageToDoc(p *gcse.Package) gcse.DocInfo {
 		ReadmeFn:    p.ReadmeFn,
 		ReadmeData:  p.ReadmeData,
 		Exported:    p.Exported,
+		ForkOf:      p.ForkOf,
 	}
 
 	d.Imports = nil
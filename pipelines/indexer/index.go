@@ -36,6 +36,10 @@ func clearOutdatedIndex() error {
 }
 
 func doIndex() bool {
+	prevSegm, err := configs.IndexSegments().FindMaxDone()
+	if err != nil {
+		log.Printf("FindMaxDone failed: %v", err)
+	}
 	idxSegm, err := configs.IndexSegments().GenMaxSegment()
 	if err != nil {
 		log.Printf("GenMaxSegment failed: %v", err)
@@ -88,6 +92,8 @@ func doIndex() bool {
 	log.Printf("Indexing success: %s (%d)", idxSegm, ts.DocCount())
 	gcse.AddBiValueAndProcess(bi.Average, "index.doc-count", ts.DocCount())
 
+	notifyRankChanges(prevSegm, idxSegm)
+
 	ts = nil
 	utils.DumpMemStats()
 	runtime.GC()
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"log"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/gcse/utils"
+	"github.com/daviddengcn/go-index"
+)
+
+func forEachHit(segm utils.Segment, f func(hit *gcse.HitInfo)) error {
+	hits, err := index.OpenConstArray(segm.Join(gcse.HitsArrFn))
+	if err != nil {
+		return err
+	}
+	defer hits.Close()
+	return hits.ForEachGob(func(_ int, v interface{}) error {
+		hit := v.(gcse.HitInfo)
+		f(&hit)
+		return nil
+	})
+}
+
+// notifyRankChanges posts the notable static rank changes, see
+// gcse.IsNotableRankChange, between the index segments prev and cur, of the
+// packages subscribed by webhooks.
+func notifyRankChanges(prev, cur utils.Segment) {
+	hooks, err := gcse.LoadWebhooks()
+	if err != nil {
+		log.Printf("LoadWebhooks failed: %v", err)
+		return
+	}
+	if len(hooks) == 0 || prev == "" {
+		return
+	}
+	notifier := gcse.NewWebhookNotifier(hooks)
+	oldRanks := make(map[string]int)
+	if err := forEachHit(prev, func(hit *gcse.HitInfo) {
+		if notifier.Matches(&hit.DocInfo) {
+			oldRanks[hit.Package] = hit.StaticRank
+		}
+	}); err != nil {
+		log.Printf("Reading hits of %v failed: %v", prev, err)
+		return
+	}
+	if err := forEachHit(cur, func(hit *gcse.HitInfo) {
+		if old, ok := oldRanks[hit.Package]; ok && gcse.IsNotableRankChange(old+1, hit.StaticRank+1) {
+			notifier.Add(&hit.DocInfo, gcse.WebhookEvent{
+				Type:    gcse.WebhookRank,
+				OldRank: old + 1,
+				NewRank: hit.StaticRank + 1,
+			})
+		}
+	}); err != nil {
+		log.Printf("Reading hits of %v failed: %v", cur, err)
+		return
+	}
+	notifier.Send()
+}
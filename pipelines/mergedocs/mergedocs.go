@@ -38,6 +38,12 @@ func main() {
 
 	var cntDeleted, cntUpdated, cntNew, cntUnchanged int64
 
+	hooks, err := gcse.LoadWebhooks()
+	if err != nil {
+		log.Printf("LoadWebhooks failed: %v", err)
+	}
+	notifier := gcse.NewWebhookNotifier(hooks)
+
 	job := mr.MrJob{
 		Source: []mr.Input{
 			kv.DirInput(fpDataRoot.Join(configs.FnDocs)),   // 0
@@ -91,10 +97,11 @@ func main() {
 						}
 					}
 
-					var act gcse.DocInfo
+					var act, original gcse.DocInfo
 					isSet := false
 					isUpdated := false
 					hasOriginal := false
+					isDeleted := false
 					for {
 						val, err := nextVal()
 						if errorsp.Cause(err) == io.EOF {
@@ -107,12 +114,12 @@ func main() {
 						cur := val.(*gcse.NewDocAction)
 						switch cur.Action {
 						case gcse.NDA_DEL:
-							// not collect out to delete it
-							atomic.AddInt64(&cntDeleted, 1)
-							return nil
+							isDeleted = true
+							continue
 
 						case gcse.NDA_ORIGINAL:
 							hasOriginal = true
+							original = cur.DocInfo
 						}
 
 						if !isSet {
@@ -126,13 +133,23 @@ func main() {
 						}
 					}
 
+					if isDeleted {
+						// not collect out to delete it
+						atomic.AddInt64(&cntDeleted, 1)
+						if hasOriginal {
+							notifier.Add(&original, gcse.WebhookEvent{Type: gcse.WebhookRemoved})
+						}
+						return nil
+					}
 					if isSet {
 						if isUpdated {
 							atomic.AddInt64(&cntUpdated, 1)
+							notifier.Add(&act, gcse.WebhookEvent{Type: gcse.WebhookUpdated})
 						} else if hasOriginal {
 							atomic.AddInt64(&cntUnchanged, 1)
 						} else {
 							atomic.AddInt64(&cntNew, 1)
+							notifier.Add(&act, gcse.WebhookEvent{Type: gcse.WebhookAdded})
 						}
 						return c[0].Collect(key, &act)
 					} else {
@@ -169,4 +186,6 @@ func main() {
 	}
 
 	log.Println("Merging success...")
+
+	notifier.Send()
 }
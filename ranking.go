@@ -143,6 +143,21 @@ func effectiveImported(imported []string, author, project string) float64 {
 	return s
 }
 
+// The weight of the PageRank in the importance of a package by its importers,
+// the rest is of effectiveImported.
+const pageRankWeight = 0.5
+
+// importedScore returns the importance of the package of doc by its
+// importers, blending effectiveImported with doc.PageRank, of the same scale,
+// if calculated.
+func importedScore(doc *HitInfo, author, project string) float64 {
+	s := effectiveImported(doc.Imported, author, project)
+	if doc.PageRank <= 0 {
+		return s
+	}
+	return (1-pageRankWeight)*s + pageRankWeight*doc.PageRank
+}
+
 var (
 	googleCodeReadonlyDate = time.Date(2015, time.August, 24, 0, 0, 0, 0, time.UTC)
 	googleCodeCloseDate    = time.Date(2016, time.January, 25, 0, 0, 0, 0, time.UTC)
@@ -171,7 +186,7 @@ func CalcStaticScore(doc *HitInfo) float64 {
 
 	project := ProjectOfPackage(doc.Package)
 
-	s += effectiveImported(doc.Imported, author, project)
+	s += importedScore(doc, author, project)
 
 	desc := strings.TrimSpace(doc.Description)
 	if len(desc) > 0 {
@@ -327,11 +342,52 @@ func removeHost(pkg string) string {
 	return pkg
 }
 
+// TokenMatch is the contribution of a query token to the match score.
+type TokenMatch struct {
+	Token   string  `json:"token"`
+	TextIdf float64 `json:"textidf"`
+	NameIdf float64 `json:"nameidf"`
+	// Fields matching the token, out of "synopsis", "sentences", "name" and
+	// "package".
+	Fields []string `json:"fields"`
+	Score  float64  `json:"score"`
+}
+
+func (m *TokenMatch) add(field string, score float64) {
+	if m == nil {
+		return
+	}
+	m.Fields = append(m.Fields, field)
+	m.Score += score
+}
+
+// BaseMatchScore returns the part of the match score independent of the
+// matches of tokens.
+func BaseMatchScore(tokenCount int) float64 {
+	if tokenCount == 0 {
+		return 1.
+	}
+	return 0.02 * float64(tokenCount)
+}
+
 func CalcMatchScore(doc *HitInfo, tokenList []string, textIdfs, nameIdfs []float64) float64 {
+	return calcMatchScore(doc, tokenList, textIdfs, nameIdfs, nil)
+}
+
+// ExplainMatchScore returns the same score as CalcMatchScore together with the
+// contribution of each token. The score is the sum of BaseMatchScore and the
+// contributions.
+func ExplainMatchScore(doc *HitInfo, tokenList []string, textIdfs, nameIdfs []float64) (float64, []TokenMatch) {
+	matches := make([]TokenMatch, len(tokenList))
+	return calcMatchScore(doc, tokenList, textIdfs, nameIdfs, matches), matches
+}
+
+// calcMatchScore fills matches, if not nil, with the contribution of each token.
+func calcMatchScore(doc *HitInfo, tokenList []string, textIdfs, nameIdfs []float64, matches []TokenMatch) float64 {
 	if len(tokenList) == 0 {
-		return 1.
+		return BaseMatchScore(0)
 	}
-	s := float64(0.02 * float64(len(tokenList)))
+	s := BaseMatchScore(len(tokenList))
 
 	filteredSyn := filterURLs([]byte(doc.Synopsis))
 	synopsis := string(bytes.ToLower(filteredSyn))
@@ -354,18 +410,131 @@ func CalcMatchScore(doc *HitInfo, tokenList []string, textIdfs, nameIdfs []float
 	for i, token := range tokenList {
 		textIdf := textIdfs[i]
 		nameIdf := nameIdfs[i]
+		var m *TokenMatch
+		if matches != nil {
+			m = &matches[i]
+			*m = TokenMatch{Token: token, TextIdf: textIdf, NameIdf: nameIdf}
+		}
 
 		if matchToken(token, synopsis, synTokens) {
 			s += 0.25 * textIdf
+			m.add("synopsis", 0.25*textIdf)
 		}
 		if matchToken(token, isText, isTokens) {
 			s += 0.25 * textIdf
+			m.add("sentences", 0.25*textIdf)
 		}
 		if matchToken(token, name, nameTokens) {
 			s += 0.25 * nameIdf
+			m.add("name", 0.25*nameIdf)
 		}
 		if matchToken(token, pkg, pkgTokens) {
 			s += 0.1 * textIdf
+			m.add("package", 0.1*textIdf)
+		}
+	}
+	return s
+}
+
+// BM25Stats are the statistics of all packages used by CalcBM25FScore.
+type BM25Stats struct {
+	DocCount int
+	// Sums of the field lengths of all packages.
+	TextLens, NameLens, PkgLens int
+}
+
+// Add adds the field lengths of doc.
+func (s *BM25Stats) Add(doc *HitInfo) {
+	s.DocCount++
+	s.TextLens += doc.TextStats.Len
+	_, n := CountTokens(nil, []byte(doc.Name))
+	s.NameLens += n
+	_, n = CountTokens(nil, []byte(removeHost(doc.Package)))
+	s.PkgLens += n
+}
+
+func (s *BM25Stats) avgLen(lens int) float64 {
+	if s.DocCount == 0 {
+		return 0
+	}
+	return float64(lens) / float64(s.DocCount)
+}
+
+const bm25K1 = 1.2
+
+// bm25Field is the weight and the length normalization of a field in BM25F.
+type bm25Field struct {
+	weight, b float64
+}
+
+var (
+	bm25TextField = bm25Field{weight: 1, b: 0.75}
+	bm25NameField = bm25Field{weight: 3, b: 0.5}
+	bm25PkgField  = bm25Field{weight: 2, b: 0.5}
+)
+
+// tf returns the weighted and length normalized term frequency.
+func (f bm25Field) tf(freq, l int, avgLen float64) float64 {
+	if freq == 0 {
+		return 0
+	}
+	norm := 1.
+	if avgLen > 0 {
+		norm = 1 - f.b + f.b*float64(l)/avgLen
+	}
+	return f.weight * float64(freq) / norm
+}
+
+// BM25Idf returns the idf of a token found in df out of N packages.
+func BM25Idf(df, N int) float64 {
+	return math.Log(1 + (float64(N-df)+0.5)/(float64(df)+0.5))
+}
+
+// CalcBM25FScore is an alternative to CalcMatchScore. It returns the BM25F
+// score over the text, name and package fields, where idfs are the BM25Idf
+// of tokens in the text field and freqs their frequencies in it, e.g. by
+// TextStats.Freq of a full hit. All tokens are assumed to be in the text
+// field, which is true for a hit of the index.
+func CalcBM25FScore(doc *HitInfo, tokenList []string, freqs []int, idfs []float64, stats *BM25Stats) float64 {
+	return calcBM25FScore(doc, tokenList, freqs, idfs, stats, nil)
+}
+
+// ExplainBM25FScore returns the same score as CalcBM25FScore together with
+// the contribution of each token.
+func ExplainBM25FScore(doc *HitInfo, tokenList []string, freqs []int, idfs []float64, stats *BM25Stats) (float64, []TokenMatch) {
+	matches := make([]TokenMatch, len(tokenList))
+	return calcBM25FScore(doc, tokenList, freqs, idfs, stats, matches), matches
+}
+
+func calcBM25FScore(doc *HitInfo, tokenList []string, freqs []int, idfs []float64, stats *BM25Stats, matches []TokenMatch) float64 {
+	if len(tokenList) == 0 {
+		return 1.
+	}
+	nameFreqs, nameLen := CountTokens(nil, []byte(doc.Name))
+	pkgFreqs, pkgLen := CountTokens(nil, []byte(removeHost(doc.Package)))
+	s := 0.
+	for i, token := range tokenList {
+		fields := []string{"text"}
+		tf := bm25TextField.tf(freqs[i], doc.TextStats.Len, stats.avgLen(stats.TextLens))
+		if freq := nameFreqs[token]; freq > 0 {
+			tf += bm25NameField.tf(freq, nameLen, stats.avgLen(stats.NameLens))
+			fields = append(fields, "name")
+		}
+		if freq := pkgFreqs[token]; freq > 0 {
+			tf += bm25PkgField.tf(freq, pkgLen, stats.avgLen(stats.PkgLens))
+			fields = append(fields, "package")
+		}
+		score := idfs[i] * tf * (bm25K1 + 1) / (bm25K1 + tf)
+		s += score
+		if matches != nil {
+			// BM25F uses the same idf for all fields.
+			matches[i] = TokenMatch{
+				Token:   token,
+				TextIdf: idfs[i],
+				NameIdf: idfs[i],
+				Fields:  fields,
+				Score:   score,
+			}
 		}
 	}
 	return s
@@ -325,3 +325,64 @@ func TestAuthorOfPackage(t *testing.T) {
 		assert.Equal(t, "author of "+PKG_AUTHOR[i], AuthorOfPackage(PKG_AUTHOR[i]), PKG_AUTHOR[i+1])
 	}
 }
+
+func TestExplainMatchScore(t *testing.T) {
+	doc := &HitInfo{
+		DocInfo: DocInfo{
+			Name:     "yaml",
+			Package:  "github.com/go-yaml/yaml",
+			Synopsis: "Package yaml implements YAML support for the Go language.",
+		},
+	}
+	tokenList := []string{"yaml", "json"}
+	textIdfs, nameIdfs := []float64{1, 2}, []float64{3, 4}
+	score, matches := ExplainMatchScore(doc, tokenList, textIdfs, nameIdfs)
+	assert.Equal(t, "score", score, CalcMatchScore(doc, tokenList, textIdfs, nameIdfs))
+	assert.Equal(t, "matches", matches, []TokenMatch{{
+		Token:   "yaml",
+		TextIdf: 1,
+		NameIdf: 3,
+		Fields:  []string{"synopsis", "name", "package"},
+		Score:   0.25*1 + 0.25*3 + 0.1*1,
+	}, {
+		Token:   "json",
+		TextIdf: 2,
+		NameIdf: 4,
+	}})
+	assert.Equal(t, "sum", score, BaseMatchScore(2)+matches[0].Score+matches[1].Score)
+}
+
+func TestCalcBM25FScore(t *testing.T) {
+	newDoc := func(name, pkg, text string) *HitInfo {
+		doc := &HitInfo{DocInfo: DocInfo{Name: name, Package: pkg}}
+		doc.TextStats = NewFieldStats(name, pkg, text)
+		return doc
+	}
+	docs := []*HitInfo{
+		newDoc("yaml", "github.com/go-yaml/yaml", "yaml support"),
+		newDoc("config", "github.com/user/config", "config file in yaml"),
+		newDoc("config", "github.com/user/config2", "config file in yaml, or json, or toml, or xml, or ini"),
+	}
+	var stats BM25Stats
+	for _, doc := range docs {
+		stats.Add(doc)
+	}
+	assert.Equal(t, "stats.DocCount", stats.DocCount, 3)
+
+	tokenList := []string{"yaml"}
+	idfs := []float64{BM25Idf(3, 3)}
+	scores := make([]float64, len(docs))
+	for i, doc := range docs {
+		freqs := []int{doc.TextStats.Freq("yaml")}
+		scores[i] = CalcBM25FScore(doc, tokenList, freqs, idfs, &stats)
+		score, matches := ExplainBM25FScore(doc, tokenList, freqs, idfs, &stats)
+		assert.Equal(t, "explained score", score, scores[i])
+		assert.Equal(t, "matches[0].Score", matches[0].Score, score)
+	}
+	// Matching name and package
+	assert.True(t, "scores[0] > scores[1]", scores[0] > scores[1])
+	// Longer text
+	assert.True(t, "scores[1] > scores[2]", scores[1] > scores[2])
+
+	assert.Equal(t, "no tokens", CalcBM25FScore(docs[0], nil, nil, nil, &stats), 1.)
+}
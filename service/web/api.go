@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -40,18 +41,31 @@ type SearchApiHit struct {
 	Synopsis    string `json:"synopsis"`
 	Description string `json:"description"`
 	ProjectURL  string `json:"projecturl"`
+	// Symbols are the exported identifiers matching the "sym:" qualifiers.
+	Symbols []string `json:"symbols,omitempty"`
+	// Forks are the packages in forks collapsed into this hit.
+	Forks []string `json:"forks,omitempty"`
+	// Explain is set only if explain=1.
+	Explain *Explanation `json:"explain,omitempty"`
 }
 
 type SearchApiStruct struct {
-	Q    string          `json:"query"`
-	Hits []*SearchApiHit `json:"hits"`
+	Q         string  `json:"query"`
+	Corrected string  `json:"corrected,omitempty"`
+	Facets    *Facets `json:"facets,omitempty"`
+	// Expansions maps query words to their synonyms also searched.
+	Expansions map[string][]string `json:"expansions,omitempty"`
+	Hits       []*SearchApiHit     `json:"hits"`
 }
 
 const MAX_API_SEARCH_HITS = 100
 
 func SearchResultToApi(q string, res *SearchResult) *SearchApiStruct {
 	apiRes := SearchApiStruct{
-		Q: q,
+		Q:          q,
+		Corrected:  res.Corrected,
+		Facets:     res.Facets,
+		Expansions: res.Expansions,
 	}
 	for i, hit := range res.Hits {
 		if i >= MAX_API_SEARCH_HITS {
@@ -64,6 +78,11 @@ func SearchResultToApi(q string, res *SearchResult) *SearchApiStruct {
 			Synopsis:    hit.Synopsis,
 			Description: hit.Description,
 			ProjectURL:  hit.ProjectURL,
+			Symbols:     hit.Symbols,
+			Explain:     hit.Explain,
+		}
+		for _, fork := range hit.Forks {
+			apiHit.Forks = append(apiHit.Forks, fork.Package)
 		}
 		apiRes.Hits = append(apiRes.Hits, apiHit)
 	}
@@ -102,10 +121,107 @@ type PackageDependenceInfo struct {
 	TestImported []string
 }
 
+// Number of lines written between flushes of a streamed response.
+const streamFlushLines = 1000
+
+// packageFilter returns whether a package matches the "site" and "prefix"
+// parameters, if not empty.
+func packageFilter(r *http.Request) func(pkg string) bool {
+	site := strings.ToLower(strings.TrimSpace(r.FormValue("site")))
+	prefix := strings.TrimSpace(r.FormValue("prefix"))
+	return func(pkg string) bool {
+		if site != "" && strings.ToLower(gcse.HostOfPackage(pkg)) != site {
+			return false
+		}
+		return strings.HasPrefix(pkg, prefix)
+	}
+}
+
+// packagesOfDocs returns the sorted packages of docs.
+func packagesOfDocs(db database, docs []int32) []string {
+	pkgs := make([]string, 0, len(docs))
+	for _, docID := range docs {
+		if hit, found := db.PackageOfDoc(docID); found {
+			pkgs = append(pkgs, hit.Package)
+		}
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// forEachPackage calls out with the in-memory hit of every package matching
+// packageFilter, until out returns an error or the client goes away.
+func forEachPackage(r *http.Request, db database, out func(doc *gcse.HitInfo) error) error {
+	match := packageFilter(r)
+	done := r.Context().Done()
+	return db.Search(nil, func(_ int32, data interface{}) error {
+		select {
+		case <-done:
+			return r.Context().Err()
+		default:
+		}
+		doc := data.(gcse.HitInfo)
+		if !match(doc.Package) {
+			return nil
+		}
+		return out(&doc)
+	})
+}
+
+// writePackages writes line(doc) of every package matching packageFilter, as
+// a JSON array, wrapped by callback if not empty, or as NDJSON, i.e. one JSON
+// value per line, if the "format" parameter is "ndjson". The values are
+// written while iterating the packages, so that the whole list is never kept
+// in memory.
+func writePackages(w http.ResponseWriter, r *http.Request, db database, callback string, line func(doc *gcse.HitInfo) interface{}) {
+	ndjson := r.FormValue("format") == "ndjson"
+	// Written before and after the values.
+	head, tail := "[", "]"
+	switch {
+	case ndjson:
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		head, tail = "", ""
+	case callback != "":
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		head, tail = fmt.Sprintf("%s(%d, [", callback, http.StatusOK), "]);"
+	default:
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(head)); err != nil {
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	lines := 0
+	if err := forEachPackage(r, db, func(doc *gcse.HitInfo) error {
+		value := jsonp.MarshalIgnoreError(line(doc))
+		if ndjson {
+			value = append(value, '\n')
+		} else if lines > 0 {
+			value = append([]byte{','}, value...)
+		}
+		if _, err := w.Write(value); err != nil {
+			return err
+		}
+		if lines++; lines%streamFlushLines == 0 && flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}); err != nil {
+		log.Printf("Streaming packages stopped: %v", err)
+		return
+	}
+	w.Write([]byte(tail))
+}
+
+// pageApi serves the original API, switching on the "action" parameter. It is
+// kept for compatibility, new clients should use API v2 served by pageApiV2.
 func pageApi(w http.ResponseWriter, r *http.Request) {
 	tr := trace.New("pageApi", r.URL.Path)
 	defer tr.Finish()
 
+	w.Header().Set("Link", `<`+apiV2Path+`>; rel="successor-version"`)
+
 	action := strings.ToLower(r.FormValue("action"))
 	callback := strings.TrimSpace(r.FormValue("callback"))
 	callback = filterFunc(callback, func(r rune) bool {
@@ -154,6 +270,45 @@ func pageApi(w http.ResponseWriter, r *http.Request) {
 			doc.StaticRank + 1,
 		}, callback)
 
+	case "similar":
+		bi.Inc("api.similar")
+		id := r.FormValue("id")
+		n, _ := strconv.Atoi(r.FormValue("len"))
+		if n <= 0 {
+			n = defaultSimilarCount
+		} else if n > maxSimilarCount {
+			n = maxSimilarCount
+		}
+		db := getDatabase()
+		if _, found := db.FindFullPackage(id); !found {
+			apiContent(w, http.StatusNotFound, fmt.Sprintf("Package %s not found!", id), callback)
+			return
+		}
+		similar := db.SimilarPackages(id, n)
+		if similar == nil {
+			similar = []SimilarPackage{}
+		}
+		apiContent(w, http.StatusOK, struct {
+			Package string           `json:"package"`
+			Similar []SimilarPackage `json:"similar"`
+		}{id, similar}, callback)
+
+	case "importers":
+		bi.Inc("api.importers")
+		id := r.FormValue("id")
+		depth, _ := strconv.Atoi(r.FormValue("depth"))
+		if depth <= 0 {
+			depth = defaultImportersDepth
+		} else if depth > maxImportersDepth {
+			depth = maxImportersDepth
+		}
+		importers, found := findTransitiveImporters(getDatabase(), id, depth, r.FormValue("tests") == "1")
+		if !found {
+			apiContent(w, http.StatusNotFound, fmt.Sprintf("Package %s not found!", id), callback)
+			return
+		}
+		apiContent(w, http.StatusOK, importers, callback)
+
 	case "tops":
 		bi.Inc("api.tops")
 		N, _ := strconv.Atoi(r.FormValue("len"))
@@ -166,51 +321,105 @@ func pageApi(w http.ResponseWriter, r *http.Request) {
 
 	case "packages":
 		bi.Inc("api.packages")
-		db := getDatabase()
-		var pkgs []string
-		if db != nil {
-			pkgs = make([]string, 0, db.PackageCount())
-			db.Search(nil, func(docID int32, data interface{}) error {
-				doc := data.(gcse.HitInfo)
-				pkgs = append(pkgs, doc.Package)
-
-				return nil
-			})
-		}
-		apiContent(w, http.StatusOK, pkgs, callback)
+		writePackages(w, r, getDatabase(), callback, func(doc *gcse.HitInfo) interface{} {
+			return doc.Package
+		})
 
 	case "package_depends":
 		bi.Inc("api.package_depends")
 		db := getDatabase()
-		var pkgs []PackageDependenceInfo
-		if db != nil {
-			pkgs = make([]PackageDependenceInfo, 0, db.PackageCount())
-			if err := db.ForEachFullPackage(func(doc gcse.HitInfo) error {
-				pkgs = append(pkgs, PackageDependenceInfo{
-					Name:         doc.Name,
-					Package:      doc.Package,
-					Imports:      doc.Imports,
-					TestImports:  doc.TestImports,
-					Imported:     doc.Imported,
-					TestImported: doc.TestImported,
-				})
-				return nil
-			}); err != nil {
-				log.Printf("ForEachFullPackage failed: %v", err)
+		writePackages(w, r, db, callback, func(doc *gcse.HitInfo) interface{} {
+			return PackageDependenceInfo{
+				Name:         doc.Name,
+				Package:      doc.Package,
+				Imports:      doc.Imports,
+				TestImports:  doc.TestImports,
+				Imported:     packagesOfDocs(db, db.TokenDocs(gcse.IndexImportsField, doc.Package)),
+				TestImported: packagesOfDocs(db, db.TokenDocs(gcse.IndexTestImportsField, doc.Package)),
 			}
+		})
+
+	case "graph":
+		bi.Inc("api.graph")
+		format := r.FormValue("format")
+		if format == "" {
+			format = gcse.GraphFormatJSON
+		}
+		contentType, ok := graphContentTypes[format]
+		if !ok {
+			apiContent(w, http.StatusBadRequest, fmt.Sprintf("Unknown graph format: %s", format), callback)
+			return
+		}
+		id, project := r.FormValue("id"), r.FormValue("project")
+		if id == "" && project == "" {
+			apiContent(w, http.StatusBadRequest, "Missing parameter: id or project", callback)
+			return
+		}
+		g := &gcse.ImportGraph{
+			Tests:    r.FormValue("tests") == "1",
+			Projects: r.FormValue("projects") == "1",
+		}
+		found, err := buildImportGraph(getDatabase(), g, id, project)
+		if err != nil {
+			apiContent(w, http.StatusInternalServerError, err.Error(), callback)
+			return
+		}
+		if !found && id != "" {
+			apiContent(w, http.StatusNotFound, fmt.Sprintf("Package %s not found!", id), callback)
+			return
+		}
+		if !found {
+			apiContent(w, http.StatusNotFound, fmt.Sprintf("Project %s not found!", project), callback)
+			return
+		}
+		w.Header().Set("Content-Type", contentType)
+		if err := gcse.WriteImportGraph(w, g, format); err != nil {
+			log.Printf("WriteImportGraph failed: %v", err)
 		}
-		apiContent(w, http.StatusOK, pkgs, callback)
 
 	case "search":
 		bi.Inc("api.search")
 		q := strings.TrimSpace(r.FormValue("q"))
-		results, _, err := search(tr, getDatabase(), q)
+		db := getDatabase()
+		results, _, err := search(tr, db, q, searchOptions{
+			Limit:  MAX_API_SEARCH_HITS,
+			Scorer: r.FormValue("scorer"),
+		})
 		if err != nil {
 			apiContent(w, http.StatusInternalServerError, err.Error(), callback)
 			return
 		}
+		if r.FormValue("explain") == "1" {
+			explainHits(results)
+		}
 		apiContent(w, http.StatusOK, SearchResultToApi(q, results), callback)
 
+	case "suggest":
+		bi.Inc("api.suggest")
+		q := strings.TrimSpace(r.FormValue("q"))
+		n, _ := strconv.Atoi(r.FormValue("len"))
+		if n <= 0 {
+			n = defaultSuggestCount
+		} else if n > maxSuggestCount {
+			n = maxSuggestCount
+		}
+		var suggestions []string
+		if q != "" {
+			suggestions = getDatabase().Suggest(q, n)
+		}
+		if suggestions == nil {
+			suggestions = []string{}
+		}
+		if r.FormValue("format") == "opensearch" {
+			// The OpenSearch suggestions format: [<query>, [<completions>...]]
+			apiContent(w, http.StatusOK, []interface{}{q, suggestions}, callback)
+			return
+		}
+		apiContent(w, http.StatusOK, struct {
+			Q           string   `json:"query"`
+			Suggestions []string `json:"suggestions"`
+		}{q, suggestions}, callback)
+
 	default:
 		bi.Inc("api.unknown")
 		apiContent(w, http.StatusBadRequest, fmt.Sprintf("Unknown action: %s", action), callback)
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestWritePackages(t *testing.T) {
+	var db hitsDB
+	for _, pkg := range []string{"github.com/a/x", "github.com/a/y", "github.com/b/z", "golang.org/x/net"} {
+		db.hits = append(db.hits, gcse.HitInfo{DocInfo: gcse.DocInfo{Package: pkg}})
+	}
+	line := func(doc *gcse.HitInfo) interface{} { return doc.Package }
+
+	w := httptest.NewRecorder()
+	writePackages(w, httptest.NewRequest("GET", "/api?action=packages", nil), db, "", line)
+	assert.Equal(t, "Content-Type", w.Header().Get("Content-Type"), "application/json; charset=utf-8")
+	assert.Equal(t, "body", w.Body.String(), `["github.com/a/x","github.com/a/y","github.com/b/z","golang.org/x/net"]`)
+
+	w = httptest.NewRecorder()
+	writePackages(w, httptest.NewRequest("GET", "/api?action=packages&prefix=golang.org/", nil), db, "cb", line)
+	assert.Equal(t, "body", w.Body.String(), `cb(200, ["golang.org/x/net"]);`)
+
+	w = httptest.NewRecorder()
+	writePackages(w, httptest.NewRequest("GET", "/api?action=packages&prefix=none/", nil), db, "", line)
+	assert.Equal(t, "body", w.Body.String(), `[]`)
+
+	w = httptest.NewRecorder()
+	writePackages(w, httptest.NewRequest("GET", "/api?action=packages&format=ndjson", nil), db, "", line)
+	assert.Equal(t, "Content-Type", w.Header().Get("Content-Type"), "application/x-ndjson; charset=utf-8")
+	assert.Equal(t, "body", w.Body.String(), `"github.com/a/x"
+"github.com/a/y"
+"github.com/b/z"
+"golang.org/x/net"
+`)
+
+	w = httptest.NewRecorder()
+	writePackages(w, httptest.NewRequest("GET", "/api?action=packages&format=ndjson&site=GitHub.com&prefix=github.com/a/", nil), db, "", line)
+	assert.Equal(t, "body", w.Body.String(), `"github.com/a/x"
+"github.com/a/y"
+`)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = httptest.NewRecorder()
+	writePackages(w, httptest.NewRequest("GET", "/api?action=packages&format=ndjson", nil).WithContext(ctx), db, "", line)
+	assert.Equal(t, "body", w.Body.String(), "")
+}
+
+func TestPackagesOfDocs(t *testing.T) {
+	db := hitsDB{hits: []gcse.HitInfo{
+		{DocInfo: gcse.DocInfo{Package: "a", Imports: []string{"c"}}},
+		{DocInfo: gcse.DocInfo{Package: "b", TestImports: []string{"c"}}},
+		{DocInfo: gcse.DocInfo{Package: "c"}},
+		{DocInfo: gcse.DocInfo{Package: "0", Imports: []string{"c"}}},
+	}}
+	assert.Equal(t, "importers", packagesOfDocs(db, db.TokenDocs(gcse.IndexImportsField, "c")), []string{"0", "a"})
+	assert.Equal(t, "test importers", packagesOfDocs(db, db.TokenDocs(gcse.IndexTestImportsField, "c")), []string{"b"})
+}
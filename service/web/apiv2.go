@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/golangplus/encoding/json"
+	"golang.org/x/net/trace"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/go-easybi"
+)
+
+const (
+	apiV2Path = "/api/v2/"
+
+	defaultApiV2PageSize = 100
+	maxApiV2PageSize     = 1000
+	defaultApiV2Tops     = 20
+	maxApiV2Tops         = 100
+	// The search ranks offset+limit hits for a page, so deeper pages are
+	// rejected.
+	maxApiV2SearchOffset = 1000
+)
+
+// ApiV2Error is the error object returned, with the same status code, by API
+// v2 as {"error": {...}}.
+type ApiV2Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// ApiV2Package is a package returned by API v2.
+type ApiV2Package struct {
+	Package string `json:"package"`
+	Name    string `json:"name"`
+	// Description is only returned for a single package.
+	Description  string   `json:"description,omitempty"`
+	Synopsis     string   `json:"synopsis"`
+	Author       string   `json:"author"`
+	ProjectURL   string   `json:"projecturl"`
+	StarCount    int      `json:"stars"`
+	StaticRank   int      `json:"staticrank"`
+	ForkOf       string   `json:"forkof,omitempty"`
+	Imports      []string `json:"imports"`
+	TestImports  []string `json:"testimports"`
+	Imported     []string `json:"imported"`
+	TestImported []string `json:"testimported"`
+}
+
+// ApiV2TopList is a list of top packages of API v2.
+type ApiV2TopList struct {
+	Name  string         `json:"name"`
+	Info  string         `json:"info"`
+	Items []ApiV2TopItem `json:"items"`
+}
+
+// ApiV2TopItem is a package, or a link if Package is empty, of a top list.
+type ApiV2TopItem struct {
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+	Link    string `json:"link,omitempty"`
+	Info    string `json:"info"`
+}
+
+func apiV2Content(w http.ResponseWriter, code int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	w.Write(jsonp.MarshalIgnoreError(obj))
+}
+
+func apiV2Error(w http.ResponseWriter, code int, format string, args ...interface{}) {
+	apiV2Content(w, code, struct {
+		Error ApiV2Error `json:"error"`
+	}{ApiV2Error{Code: code, Message: fmt.Sprintf(format, args...)}})
+}
+
+// apiV2Page parses the "cursor" and "limit" parameters. A cursor is opaque to
+// clients, and is only valid for the index it is returned from. Offsets beyond
+// maxOffset are invalid.
+func apiV2Page(r *http.Request, defLimit, maxLimit, maxOffset int) (offset, limit int, err error) {
+	if c := r.FormValue("cursor"); c != "" {
+		if offset, err = strconv.Atoi(c); err != nil || offset < 0 || offset > maxOffset {
+			return 0, 0, fmt.Errorf("invalid cursor: %q", c)
+		}
+	}
+	limit = defLimit
+	if l := r.FormValue("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil || limit <= 0 {
+			return 0, 0, fmt.Errorf("invalid limit: %q", l)
+		}
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+	}
+	return offset, limit, nil
+}
+
+// apiV2NextCursor returns the cursor of the page after [offset, end), or ""
+// if there are no more than end items.
+func apiV2NextCursor(end, total int) string {
+	if end >= total {
+		return ""
+	}
+	return strconv.Itoa(end)
+}
+
+// pageApiV2 serves API v2:
+//
+//	GET /api/v2/packages           all packages, paginated
+//	GET /api/v2/packages/{path}    a package
+//	GET /api/v2/search?q=...       search results, paginated
+//	GET /api/v2/tops               top packages
+//
+// Cross-origin requests are allowed by CORS headers.
+func pageApiV2(w http.ResponseWriter, r *http.Request) {
+	tr := trace.New("pageApiV2", r.URL.Path)
+	defer tr.Finish()
+
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	switch r.Method {
+	case "GET", "HEAD":
+	case "OPTIONS":
+		// A CORS preflight request.
+		w.Header().Set("Access-Control-Allow-Methods", "GET, HEAD, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Max-Age", "86400")
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+		apiV2Error(w, http.StatusMethodNotAllowed, "Method %s not allowed", r.Method)
+		return
+	}
+	db := getDatabase()
+	route := strings.TrimPrefix(r.URL.Path, apiV2Path)
+	switch {
+	case route == "packages":
+		bi.Inc("apiv2.packages")
+		apiV2Packages(w, r, db)
+	case strings.HasPrefix(route, "packages/"):
+		bi.Inc("apiv2.package")
+		apiV2GetPackage(w, db, strings.TrimPrefix(route, "packages/"))
+	case route == "search":
+		bi.Inc("apiv2.search")
+		apiV2Search(tr, w, r, db)
+	case route == "tops":
+		bi.Inc("apiv2.tops")
+		apiV2Tops(w, r)
+	default:
+		bi.Inc("apiv2.unknown")
+		apiV2Error(w, http.StatusNotFound, "Unknown resource: %s", r.URL.Path)
+	}
+}
+
+func toApiV2Package(hit *gcse.HitInfo) *ApiV2Package {
+	return &ApiV2Package{
+		Package:      hit.Package,
+		Name:         hit.Name,
+		Synopsis:     hit.Synopsis,
+		Author:       hit.Author,
+		ProjectURL:   hit.ProjectURL,
+		StarCount:    hit.StarCount,
+		StaticRank:   hit.StaticRank + 1,
+		ForkOf:       hit.ForkOf,
+		Imports:      hit.Imports,
+		TestImports:  hit.TestImports,
+		Imported:     hit.Imported,
+		TestImported: hit.TestImported,
+	}
+}
+
+func apiV2Packages(w http.ResponseWriter, r *http.Request, db database) {
+	offset, limit, err := apiV2Page(r, defaultApiV2PageSize, maxApiV2PageSize, db.PackageCount())
+	if err != nil {
+		apiV2Error(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	total := db.PackageCount()
+	end := offset + limit
+	if end > total {
+		end = total
+	}
+	pkgs := []*ApiV2Package{}
+	for docID := offset; docID < end; docID++ {
+		hit, found := db.FullPackageOfDoc(int32(docID))
+		if !found {
+			apiV2Error(w, http.StatusInternalServerError, "Reading package %d failed", docID)
+			return
+		}
+		pkgs = append(pkgs, toApiV2Package(&hit))
+	}
+	apiV2Content(w, http.StatusOK, struct {
+		Packages   []*ApiV2Package `json:"packages"`
+		Total      int             `json:"total"`
+		NextCursor string          `json:"next_cursor,omitempty"`
+	}{pkgs, total, apiV2NextCursor(end, total)})
+}
+
+func apiV2GetPackage(w http.ResponseWriter, db database, id string) {
+	hit, found := db.FindFullPackage(id)
+	if !found {
+		apiV2Error(w, http.StatusNotFound, "Package %s not found", id)
+		return
+	}
+	pkg := toApiV2Package(&hit)
+	pkg.Description = hit.Description
+	apiV2Content(w, http.StatusOK, pkg)
+}
+
+func apiV2Search(tr trace.Trace, w http.ResponseWriter, r *http.Request, db database) {
+	q := strings.TrimSpace(r.FormValue("q"))
+	if q == "" {
+		apiV2Error(w, http.StatusBadRequest, "Missing parameter: q")
+		return
+	}
+	offset, limit, err := apiV2Page(r, MAX_API_SEARCH_HITS, MAX_API_SEARCH_HITS, maxApiV2SearchOffset)
+	if err != nil {
+		apiV2Error(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	results, _, err := search(tr, db, q, searchOptions{
+		// One more hit tells whether there is a next page.
+		Limit:  offset + limit + 1,
+		Scorer: r.FormValue("scorer"),
+	})
+	if err != nil {
+		apiV2Error(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	hits := results.Hits
+	if offset > len(hits) {
+		offset = len(hits)
+	}
+	end := offset + limit
+	if end > len(hits) {
+		end = len(hits)
+	}
+	page := *results
+	page.Hits = hits[offset:end]
+	if r.FormValue("explain") == "1" {
+		explainHits(&page)
+	}
+	apiRes := SearchResultToApi(q, &page)
+	if apiRes.Hits == nil {
+		apiRes.Hits = []*SearchApiHit{}
+	}
+	nextCursor := apiV2NextCursor(end, len(hits))
+	if end > maxApiV2SearchOffset {
+		// The cursor would be rejected.
+		nextCursor = ""
+	}
+	apiV2Content(w, http.StatusOK, struct {
+		*SearchApiStruct
+		Total      int    `json:"total"`
+		NextCursor string `json:"next_cursor,omitempty"`
+	}{apiRes, results.TotalEntries, nextCursor})
+}
+
+func apiV2Tops(w http.ResponseWriter, r *http.Request) {
+	_, n, err := apiV2Page(r, defaultApiV2Tops, maxApiV2Tops, 0)
+	if err != nil {
+		apiV2Error(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+	lists := []ApiV2TopList{}
+	for _, l := range statTops(n) {
+		list := ApiV2TopList{Name: l.Name, Info: l.Info, Items: []ApiV2TopItem{}}
+		for _, item := range l.Items {
+			list.Items = append(list.Items, ApiV2TopItem{
+				Name:    item.Name,
+				Package: item.Package,
+				Link:    item.Link,
+				Info:    item.Info,
+			})
+		}
+		lists = append(lists, list)
+	}
+	apiV2Content(w, http.StatusOK, struct {
+		Tops []ApiV2TopList `json:"tops"`
+	}{lists})
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+	"golang.org/x/net/trace"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func (db hitsDB) FullPackageOfDoc(docID int32) (gcse.HitInfo, bool) {
+	if docID < 0 || int(docID) >= len(db.hits) {
+		return gcse.HitInfo{}, false
+	}
+	return db.hits[docID], true
+}
+
+func TestApiV2Packages(t *testing.T) {
+	var db hitsDB
+	for _, pkg := range []string{"a/x", "b/y", "c/z"} {
+		db.hits = append(db.hits, gcse.HitInfo{DocInfo: gcse.DocInfo{Package: pkg}})
+	}
+	type page struct {
+		Packages []struct {
+			Package string `json:"package"`
+		} `json:"packages"`
+		Total      int    `json:"total"`
+		NextCursor string `json:"next_cursor"`
+	}
+	var pkgs []string
+	cursor := ""
+	for i := 0; i < len(db.hits); i++ {
+		w := httptest.NewRecorder()
+		apiV2Packages(w, httptest.NewRequest("GET", "/api/v2/packages?limit=2&cursor="+cursor, nil), db)
+		assert.Equal(t, "code", w.Code, http.StatusOK)
+		var p page
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, "total", p.Total, 3)
+		for _, pkg := range p.Packages {
+			pkgs = append(pkgs, pkg.Package)
+		}
+		if cursor = p.NextCursor; cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, "pkgs", pkgs, []string{"a/x", "b/y", "c/z"})
+
+	w := httptest.NewRecorder()
+	apiV2Packages(w, httptest.NewRequest("GET", "/api/v2/packages?cursor=abc", nil), db)
+	assert.Equal(t, "code", w.Code, http.StatusBadRequest)
+
+	w = httptest.NewRecorder()
+	apiV2Packages(w, httptest.NewRequest("GET", "/api/v2/packages?cursor=9223372036854775807", nil), db)
+	assert.Equal(t, "code", w.Code, http.StatusBadRequest)
+}
+
+func TestApiV2Page(t *testing.T) {
+	offset, limit, err := apiV2Page(httptest.NewRequest("GET", "/?cursor=10&limit=200", nil), 20, 100, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "offset", offset, 10)
+	assert.Equal(t, "limit", limit, 100)
+
+	_, _, err = apiV2Page(httptest.NewRequest("GET", "/?cursor=11", nil), 20, 100, 10)
+	assert.True(t, "err", err != nil)
+	_, _, err = apiV2Page(httptest.NewRequest("GET", "/?cursor=-1", nil), 20, 100, 10)
+	assert.True(t, "err", err != nil)
+}
+
+func TestApiV2GetPackage(t *testing.T) {
+	db := hitsDB{hits: []gcse.HitInfo{{DocInfo: gcse.DocInfo{Package: "a/x", Description: "desc"}}}}
+	w := httptest.NewRecorder()
+	apiV2GetPackage(w, db, "a/x")
+	assert.Equal(t, "code", w.Code, http.StatusOK)
+	var pkg ApiV2Package
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &pkg))
+	assert.Equal(t, "pkg", pkg, ApiV2Package{Package: "a/x", Description: "desc", StaticRank: 1})
+
+	w = httptest.NewRecorder()
+	apiV2GetPackage(w, db, "b/y")
+	assert.Equal(t, "code", w.Code, http.StatusNotFound)
+	var e struct {
+		Error ApiV2Error `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+	assert.Equal(t, "error", e.Error, ApiV2Error{Code: http.StatusNotFound, Message: "Package b/y not found"})
+}
+
+func TestPageApiV2(t *testing.T) {
+	w := httptest.NewRecorder()
+	pageApiV2(w, httptest.NewRequest("OPTIONS", "/api/v2/search", nil))
+	assert.Equal(t, "code", w.Code, http.StatusNoContent)
+	assert.Equal(t, "Allow-Origin", w.Header().Get("Access-Control-Allow-Origin"), "*")
+
+	w = httptest.NewRecorder()
+	pageApiV2(w, httptest.NewRequest("POST", "/api/v2/search", nil))
+	assert.Equal(t, "code", w.Code, http.StatusMethodNotAllowed)
+
+	w = httptest.NewRecorder()
+	pageApiV2(w, httptest.NewRequest("GET", "/api/v2/unknown", nil))
+	assert.Equal(t, "code", w.Code, http.StatusNotFound)
+	assert.Equal(t, "Allow-Origin", w.Header().Get("Access-Control-Allow-Origin"), "*")
+}
+
+func TestApiV2Search_paging(t *testing.T) {
+	var db hitsDB
+	for i := 0; i < maxApiV2SearchOffset+100; i++ {
+		db.hits = append(db.hits, gcse.HitInfo{
+			DocInfo:     gcse.DocInfo{Package: fmt.Sprintf("a%04d/yaml", i), Name: "yaml"},
+			StaticScore: float64(i),
+		})
+	}
+	tr := trace.New("test", "TestApiV2Search_paging")
+	defer tr.Finish()
+	type page struct {
+		Hits       []*SearchApiHit `json:"hits"`
+		Total      int             `json:"total"`
+		NextCursor string          `json:"next_cursor"`
+	}
+	// Follows next_cursor from near the boundary to the end.
+	cursor, n := "800", 0
+	for cursor != "" {
+		w := httptest.NewRecorder()
+		apiV2Search(tr, w, httptest.NewRequest("GET", "/api/v2/search?q=yaml&limit=100&cursor="+cursor, nil), db)
+		assert.Equal(t, "code", w.Code, http.StatusOK)
+		var p page
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, "Total", p.Total, len(db.hits))
+		n += len(p.Hits)
+		cursor = p.NextCursor
+	}
+	// Pages [800, 900), [900, 1000) and [1000, 1100).
+	assert.Equal(t, "n", n, 300)
+
+	w := httptest.NewRecorder()
+	apiV2Search(tr, w, httptest.NewRequest("GET", "/api/v2/search?q=yaml&limit=100&cursor=950", nil), db)
+	var p page
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+	assert.Equal(t, "len(Hits)", len(p.Hits), 100)
+	assert.Equal(t, "NextCursor", p.NextCursor, "")
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ajstarks/svgo"
+	"github.com/golangplus/encoding/json"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/gcse/configs"
+)
+
+const (
+	defaultBadgeMetric = "rank"
+	defaultBadgeStyle  = "flat"
+)
+
+var (
+	// The metrics and styles in the order shown on the badge page.
+	badgeMetrics = []string{"rank", "importers", "stars", "updated", "indexed"}
+	badgeStyles  = []string{"flat", "plastic"}
+)
+
+// Badge is a badge of a package, in the shields.io endpoint schema.
+type Badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	// Color is a hex color without "#".
+	Color string `json:"color"`
+	Style string `json:"style,omitempty"`
+}
+
+// packageBadge returns the badge of metric of the package of hit, or false if
+// metric is unknown.
+func packageBadge(hit *gcse.HitInfo, metric string, now time.Time) (Badge, bool) {
+	b := Badge{SchemaVersion: 1, Color: "007ec6"}
+	switch metric {
+	case "rank":
+		b.Label, b.Message, b.Color = "GoSearch", fmt.Sprintf("#%d", hit.StaticRank+1), "5bc0de"
+	case "importers":
+		b.Label, b.Message = "importers", strconv.Itoa(len(hit.Imported))
+	case "stars":
+		stars := hit.StarCount
+		if stars < 0 {
+			stars = 0
+		}
+		b.Label, b.Message = "stars", strconv.Itoa(stars)
+	case "updated":
+		b.Label, b.Message = "updated", hit.LastUpdated.UTC().Format("2006-01-02")
+		switch age := now.Sub(hit.LastUpdated); {
+		case age < 30*24*time.Hour:
+			b.Color = "4c1"
+		case age < 365*24*time.Hour:
+			b.Color = "dfb317"
+		default:
+			b.Color = "9f9f9f"
+		}
+	case "indexed":
+		b.Label, b.Message, b.Color = "GoSearch", "indexed", "4c1"
+	default:
+		return Badge{}, false
+	}
+	return b, true
+}
+
+// badgeTextWidth estimates the width of s in 11px Verdana.
+func badgeTextWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("ijlI.,:;'|!() ", r):
+			w += 4
+		case strings.ContainsRune("mwMW@%", r):
+			w += 11
+		case r >= 'A' && r <= 'Z', r == '#':
+			w += 8
+		default:
+			w += 7
+		}
+	}
+	return w
+}
+
+// writeBadge writes b as an SVG in style, "flat" or "plastic".
+func writeBadge(w http.ResponseWriter, b Badge, style string) {
+	w.Header().Set("Content-Type", "image/svg+xml")
+
+	H, radius, textY := 20, 3, 14
+	stops := []svg.Offcolor{{Offset: 0, Color: "#bbb", Opacity: .1}, {Offset: 100, Color: "#000", Opacity: .1}}
+	if style == "plastic" {
+		H, radius, textY = 18, 4, 13
+		stops = []svg.Offcolor{
+			{Offset: 0, Color: "#fff", Opacity: .7},
+			{Offset: 10, Color: "#aaa", Opacity: .1},
+			{Offset: 90, Color: "#000", Opacity: .3},
+			{Offset: 100, Color: "#000", Opacity: .5},
+		}
+	}
+	labelW, msgW := badgeTextWidth(b.Label)+10, badgeTextWidth(b.Message)+10
+	W := labelW + msgW
+
+	s := svg.New(w)
+	s.Start(W, H)
+	s.Title(b.Label + ": " + b.Message)
+	s.Def()
+	s.LinearGradient("s", 0, 0, 0, 100, stops)
+	s.ClipPath(`id="r"`)
+	s.Roundrect(0, 0, W, H, radius, radius, "fill:#fff")
+	s.ClipEnd()
+	s.DefEnd()
+
+	s.Group(`clip-path="url(#r)"`)
+	s.Rect(0, 0, labelW, H, "fill:#555")
+	s.Rect(labelW, 0, msgW, H, "fill:#"+b.Color)
+	s.Rect(0, 0, W, H, "fill:url(#s)")
+	s.Gend()
+
+	s.Group(`fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11"`)
+	for _, t := range []struct {
+		x    int
+		text string
+	}{{labelW / 2, b.Label}, {labelW + msgW/2, b.Message}} {
+		s.Text(t.x, textY+1, t.text, "fill:#010101;fill-opacity:.3")
+		s.Text(t.x, textY, t.text)
+	}
+	s.Gend()
+	s.End()
+}
+
+// serveBadge serves r by h, with the cache headers of the index of db unless
+// the badge of the "metric" parameter changes with time, e.g. the color of
+// "updated" with the age of the package.
+func serveBadge(w http.ResponseWriter, r *http.Request, db database, h http.HandlerFunc) {
+	if r.FormValue("metric") == "updated" {
+		h(w, r)
+		return
+	}
+	serveCachedByIndex(w, r, db, h)
+}
+
+// cachedBadge returns h served with serveBadge.
+func cachedBadge(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveBadge(w, r, getDatabase(), h)
+	}
+}
+
+// findBadge returns the badge of the "id" and "metric" parameters, or writes
+// the error and returns false.
+func findBadge(w http.ResponseWriter, r *http.Request) (Badge, bool) {
+	id := strings.TrimSpace(r.FormValue("id"))
+	doc, found := getDatabase().FindFullPackage(id)
+	if !found {
+		http.Error(w, fmt.Sprintf("Package %s not found!", id), http.StatusNotFound)
+		return Badge{}, false
+	}
+	metric := r.FormValue("metric")
+	if metric == "" {
+		metric = defaultBadgeMetric
+	}
+	b, ok := packageBadge(&doc, metric, time.Now())
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown metric: %s", metric), http.StatusBadRequest)
+		return Badge{}, false
+	}
+	return b, true
+}
+
+// pageBadge serves the SVG badge of the "id", "metric" and "style" parameters.
+func pageBadge(w http.ResponseWriter, r *http.Request) {
+	b, ok := findBadge(w, r)
+	if !ok {
+		return
+	}
+	style := r.FormValue("style")
+	if style == "" {
+		style = defaultBadgeStyle
+	}
+	writeBadge(w, b, style)
+}
+
+// pageBadgeJSON serves the badge in the shields.io endpoint schema.
+func pageBadgeJSON(w http.ResponseWriter, r *http.Request) {
+	b, ok := findBadge(w, r)
+	if !ok {
+		return
+	}
+	b.Style = r.FormValue("style")
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(jsonp.MarshalIgnoreError(b))
+}
+
+// BadgeVariant is a badge of a metric and a style with its snippets.
+type BadgeVariant struct {
+	Metric string
+	Style  string
+	ImgURL string
+	// HTMLCode and MDCode use the badge of the site, ShieldsMD the one of
+	// shields.io reading badge.json.
+	HTMLCode  string
+	MDCode    string
+	ShieldsMD string
+}
+
+func badgeVariants(pkg string) []BadgeVariant {
+	viewURL := configs.SiteURL + "/view?id=" + template.URLQueryEscaper(pkg)
+	var variants []BadgeVariant
+	for _, metric := range badgeMetrics {
+		for _, style := range badgeStyles {
+			params := "?id=" + template.URLQueryEscaper(pkg) + "&metric=" + metric + "&style=" + style
+			imgURL := configs.SiteURL + "/badge" + params
+			shieldsURL := "https://img.shields.io/endpoint?url=" + template.URLQueryEscaper(configs.SiteURL+"/badge.json"+params)
+			variants = append(variants, BadgeVariant{
+				Metric:    metric,
+				Style:     style,
+				ImgURL:    "badge" + params,
+				HTMLCode:  fmt.Sprintf(`<a href="%s"><img src="%s" alt="GoSearch"></a>`, viewURL, imgURL),
+				MDCode:    fmt.Sprintf(`[![GoSearch](%s)](%s)`, imgURL, viewURL),
+				ShieldsMD: fmt.Sprintf(`[![GoSearch](%s)](%s)`, shieldsURL, viewURL),
+			})
+		}
+	}
+	return variants
+}
+
+func pageBadgePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	id := strings.TrimSpace(r.FormValue("id"))
+	if id != "" {
+		doc, found := getDatabase().FindFullPackage(id)
+		if !found {
+			http.Error(w, fmt.Sprintf("Package %s not found!", id), http.StatusNotFound)
+			return
+		}
+		if err := templates.ExecuteTemplate(w, "badgepage.html", struct {
+			UIUtils
+			gcse.HitInfo
+			Variants []BadgeVariant
+		}{
+			HitInfo:  doc,
+			Variants: badgeVariants(doc.Package),
+		}); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestPackageBadge(t *testing.T) {
+	now := time.Date(2017, 6, 1, 0, 0, 0, 0, time.UTC)
+	hit := &gcse.HitInfo{
+		StaticRank: 9,
+		Imported:   []string{"a", "b"},
+	}
+	hit.StarCount = -1
+	hit.LastUpdated = now.Add(-24 * time.Hour)
+
+	b, ok := packageBadge(hit, "rank", now)
+	assert.True(t, "ok", ok)
+	assert.Equal(t, "rank", b, Badge{SchemaVersion: 1, Label: "GoSearch", Message: "#10", Color: "5bc0de"})
+	b, _ = packageBadge(hit, "importers", now)
+	assert.Equal(t, "importers", b.Message, "2")
+	b, _ = packageBadge(hit, "stars", now)
+	assert.Equal(t, "stars", b.Message, "0")
+	b, _ = packageBadge(hit, "updated", now)
+	assert.Equal(t, "updated", b.Message, "2017-05-31")
+	assert.Equal(t, "updated color", b.Color, "4c1")
+
+	hit.LastUpdated = now.AddDate(-2, 0, 0)
+	b, _ = packageBadge(hit, "updated", now)
+	assert.Equal(t, "old updated color", b.Color, "9f9f9f")
+
+	_, ok = packageBadge(hit, "unknown", now)
+	assert.False(t, "unknown ok", ok)
+}
+
+func TestWriteBadge(t *testing.T) {
+	b := Badge{SchemaVersion: 1, Label: "importers", Message: "12345", Color: "007ec6"}
+	for _, style := range badgeStyles {
+		w := httptest.NewRecorder()
+		writeBadge(w, b, style)
+		assert.Equal(t, "Content-Type", w.Header().Get("Content-Type"), "image/svg+xml")
+		svg := w.Body.String()
+		assert.True(t, style+" label", strings.Contains(svg, ">importers<"))
+		assert.True(t, style+" message", strings.Contains(svg, ">12345<"))
+		assert.True(t, style+" color", strings.Contains(svg, "fill:#007ec6"))
+	}
+	assert.True(t, "width grows", badgeTextWidth("importers") > badgeTextWidth("stars"))
+}
+
+func TestBadgeVariants(t *testing.T) {
+	variants := badgeVariants("github.com/a/b")
+	assert.Equal(t, "len", len(variants), len(badgeMetrics)*len(badgeStyles))
+	v := variants[0]
+	assert.Equal(t, "ImgURL", v.ImgURL, "badge?id=github.com%2Fa%2Fb&metric=rank&style=flat")
+	assert.True(t, "ShieldsMD", strings.HasPrefix(v.ShieldsMD, "[![GoSearch](https://img.shields.io/endpoint?url=http%3A%2F%2Fgo-search.org%2Fbadge.json%3Fid%3D"))
+}
+
+func TestServeBadge(t *testing.T) {
+	db := segmentDB{segment: "12", updated: time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)}
+	h := func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("badge")) }
+
+	w := httptest.NewRecorder()
+	serveBadge(w, httptest.NewRequest("GET", "/badge?id=a/b&metric=stars", nil), db, h)
+	assert.Equal(t, "ETag", w.Header().Get("ETag"), indexETag("12", "a/b"))
+
+	w = httptest.NewRecorder()
+	serveBadge(w, httptest.NewRequest("GET", "/badge?id=a/b&metric=updated", nil), db, h)
+	assert.Equal(t, "ETag", w.Header().Get("ETag"), "")
+	assert.Equal(t, "Last-Modified", w.Header().Get("Last-Modified"), "")
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// indexETag returns the weak ETag of the responses of the index segment segm,
+// with a hash of the package id if not empty. Weak because the same content
+// could be gzipped or not.
+func indexETag(segm, id string) string {
+	if id == "" {
+		return fmt.Sprintf(`W/"%s"`, segm)
+	}
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return fmt.Sprintf(`W/"%s-%08x"`, segm, h.Sum32())
+}
+
+// etagMatches returns whether the If-None-Match header value matches etag,
+// compared weakly.
+func etagMatches(ifNoneMatch, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified returns whether the conditional request r matches etag, or, if
+// it has no If-None-Match header, whether it was not modified since modified.
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && !modified.After(t)
+	}
+	return false
+}
+
+// indexCacheWriter sets the cache headers of the index only on responses of
+// status 200, so that errors are not cached.
+type indexCacheWriter struct {
+	http.ResponseWriter
+	etag, modified string
+	wroteHeader    bool
+}
+
+func (w *indexCacheWriter) setHeaders() {
+	w.Header().Set("ETag", w.etag)
+	w.Header().Set("Last-Modified", w.modified)
+	// Caches, e.g. a CDN, have to revalidate, which is cheap with the ETag.
+	w.Header().Set("Cache-Control", "public, no-cache")
+}
+
+func (w *indexCacheWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if code == http.StatusOK {
+			w.setHeaders()
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *indexCacheWriter) Write(bs []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(bs)
+}
+
+// Flush flushes the streamed responses, e.g. of writePackages.
+func (w *indexCacheWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// serveCachedByIndex serves r by h with the cache headers of the index of db
+// if the status is 200, or answers 304 if the client has the response of the
+// same index. It is only for responses which change only with the index.
+func serveCachedByIndex(w http.ResponseWriter, r *http.Request, db database, h http.HandlerFunc) {
+	segm := db.IndexSegment()
+	if segm == "" || r.Method != "GET" && r.Method != "HEAD" {
+		h(w, r)
+		return
+	}
+	etag := indexETag(segm, r.FormValue("id"))
+	modified := db.IndexUpdated().UTC().Truncate(time.Second)
+	cw := &indexCacheWriter{ResponseWriter: w, etag: etag, modified: modified.Format(http.TimeFormat)}
+	if notModified(r, etag, modified) {
+		cw.setHeaders()
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h(cw, r)
+}
+
+// cachedByIndex returns h served with serveCachedByIndex.
+func cachedByIndex(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		serveCachedByIndex(w, r, getDatabase(), h)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golangplus/testing/assert"
+)
+
+type segmentDB struct {
+	database
+	segment string
+	updated time.Time
+}
+
+func (db segmentDB) IndexSegment() string {
+	return db.segment
+}
+
+func (db segmentDB) IndexUpdated() time.Time {
+	return db.updated
+}
+
+func TestEtagMatches(t *testing.T) {
+	assert.True(t, "exact", etagMatches(`W/"1"`, `W/"1"`))
+	assert.True(t, "strong", etagMatches(`"1"`, `W/"1"`))
+	assert.True(t, "list", etagMatches(`"0", W/"1"`, `W/"1"`))
+	assert.True(t, "*", etagMatches(`*`, `W/"1"`))
+	assert.False(t, "other", etagMatches(`W/"2"`, `W/"1"`))
+}
+
+func TestServeCachedByIndex(t *testing.T) {
+	db := segmentDB{segment: "12", updated: time.Date(2017, 1, 2, 3, 4, 5, 600, time.UTC)}
+	calls := 0
+	h := func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte("body"))
+	}
+
+	w := httptest.NewRecorder()
+	serveCachedByIndex(w, httptest.NewRequest("GET", "/view?id=a/b", nil), db, h)
+	assert.Equal(t, "code", w.Code, http.StatusOK)
+	etag := w.Header().Get("ETag")
+	assert.Equal(t, "ETag", etag, indexETag("12", "a/b"))
+	assert.Equal(t, "Last-Modified", w.Header().Get("Last-Modified"), "Mon, 02 Jan 2017 03:04:05 GMT")
+	assert.Equal(t, "calls", calls, 1)
+
+	r := httptest.NewRequest("GET", "/view?id=a/b", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	serveCachedByIndex(w, r, db, h)
+	assert.Equal(t, "code", w.Code, http.StatusNotModified)
+	assert.Equal(t, "body", w.Body.String(), "")
+	assert.Equal(t, "calls", calls, 1)
+
+	// A new index segment.
+	db.segment = "13"
+	w = httptest.NewRecorder()
+	serveCachedByIndex(w, r, db, h)
+	assert.Equal(t, "code", w.Code, http.StatusOK)
+	assert.Equal(t, "calls", calls, 2)
+
+	r = httptest.NewRequest("GET", "/tops", nil)
+	r.Header.Set("If-Modified-Since", "Mon, 02 Jan 2017 03:04:05 GMT")
+	w = httptest.NewRecorder()
+	serveCachedByIndex(w, r, db, h)
+	assert.Equal(t, "code", w.Code, http.StatusNotModified)
+
+	w = httptest.NewRecorder()
+	serveCachedByIndex(w, httptest.NewRequest("POST", "/api", nil), db, h)
+	assert.Equal(t, "ETag", w.Header().Get("ETag"), "")
+	assert.Equal(t, "calls", calls, 3)
+
+	// Errors are not cached.
+	w = httptest.NewRecorder()
+	serveCachedByIndex(w, httptest.NewRequest("GET", "/view?id=x/y", nil), db, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Package x/y not found!", http.StatusNotFound)
+	})
+	assert.Equal(t, "code", w.Code, http.StatusNotFound)
+	assert.Equal(t, "ETag", w.Header().Get("ETag"), "")
+	assert.Equal(t, "Last-Modified", w.Header().Get("Last-Modified"), "")
+	assert.Equal(t, "Cache-Control", w.Header().Get("Cache-Control"), "")
+}
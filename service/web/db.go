@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -27,12 +28,38 @@ type database interface {
 	PackageCount() int
 	ProjectCount() int
 	IndexUpdated() time.Time
+	// IndexSegment returns the name of the index segment, or "" if not
+	// loaded.
+	IndexSegment() string
 	Close()
 
 	FindFullPackage(id string) (hit gcse.HitInfo, found bool)
-	ForEachFullPackage(func(gcse.HitInfo) error) error
+	// PackageOfDoc returns the in-memory hit of docID, which has no
+	// description, README or importers.
+	PackageOfDoc(docID int32) (hit gcse.HitInfo, found bool)
+	// FullPackageOfDoc returns the full package of docID, which is in
+	// [0, PackageCount()).
+	FullPackageOfDoc(docID int32) (hit gcse.HitInfo, found bool)
 	PackageCountOfToken(field, token string) int
+	// TokenDocs returns the docIDs of packages containing token in field.
+	TokenDocs(field, token string) []int32
 	Search(q map[string]stringsp.Set, out func(docID int32, data interface{}) error) error
+	// CorrectWord returns the most likely correctly spelled word in the
+	// index vocabulary.
+	CorrectWord(word string) (string, bool)
+	// Suggest returns at most n package names, import paths or popular
+	// tokens starting with prefix, ordered by static score.
+	Suggest(prefix string, n int) []string
+	// BM25Stats returns the statistics of all packages for BM25F scoring.
+	BM25Stats() *gcse.BM25Stats
+	// SimilarPackages returns at most n packages similar to the package of
+	// id, the most similar first.
+	SimilarPackages(id string, n int) []SimilarPackage
+	// PackageFeed returns the packages first seen or updated since the
+	// previous index segment.
+	PackageFeed() *packageFeed
+	// Sitemap returns the sitemap of the view pages, or nil if not loaded.
+	Sitemap() *siteMap
 }
 
 type searcherDB struct {
@@ -41,6 +68,13 @@ type searcherDB struct {
 
 	projectCount int
 	indexUpdated time.Time
+	segment      utils.Segment
+	speller      *spellChecker
+	suggester    *suggester
+	bm25Stats    gcse.BM25Stats
+	similar      similarCache
+	feed         *packageFeed
+	sitemap      *siteMap
 
 	storeDB *bh.RefCountBox
 }
@@ -66,6 +100,13 @@ func (db *searcherDB) IndexUpdated() time.Time {
 	return db.indexUpdated
 }
 
+func (db *searcherDB) IndexSegment() string {
+	if db == nil {
+		return ""
+	}
+	return db.segment.Name()
+}
+
 func (db *searcherDB) Close() {
 	if db == nil {
 		return
@@ -99,13 +140,24 @@ func (db *searcherDB) FindFullPackage(id string) (gcse.HitInfo, bool) {
 	return hit, true
 }
 
-func (db *searcherDB) ForEachFullPackage(out func(gcse.HitInfo) error) error {
-	if db == nil {
-		return nil
+func (db *searcherDB) PackageOfDoc(docID int32) (gcse.HitInfo, bool) {
+	if db == nil || docID < 0 || int(docID) >= db.PackageCount() {
+		return gcse.HitInfo{}, false
 	}
-	return db.hits.ForEachGob(func(_ int, hit interface{}) error {
-		return out(hit.(gcse.HitInfo))
-	})
+	hit, ok := db.ts.DocInfo(docID).(gcse.HitInfo)
+	return hit, ok
+}
+
+func (db *searcherDB) FullPackageOfDoc(docID int32) (gcse.HitInfo, bool) {
+	if db == nil || docID < 0 || int(docID) >= db.PackageCount() {
+		return gcse.HitInfo{}, false
+	}
+	h, err := db.hits.GetGob(int(docID))
+	if err != nil {
+		log.Printf("GetGob %d failed: %v", docID, err)
+		return gcse.HitInfo{}, false
+	}
+	return h.(gcse.HitInfo), true
 }
 
 func (db *searcherDB) PackageCountOfToken(field, token string) int {
@@ -115,6 +167,13 @@ func (db *searcherDB) PackageCountOfToken(field, token string) int {
 	return len(db.ts.TokenDocList(field, token))
 }
 
+func (db *searcherDB) TokenDocs(field, token string) []int32 {
+	if db == nil {
+		return nil
+	}
+	return db.ts.TokenDocList(field, token)
+}
+
 func (db *searcherDB) Search(q map[string]stringsp.Set, out func(docID int32, data interface{}) error) error {
 	if db == nil {
 		return nil
@@ -122,6 +181,58 @@ func (db *searcherDB) Search(q map[string]stringsp.Set, out func(docID int32, da
 	return db.ts.Search(q, out)
 }
 
+func (db *searcherDB) CorrectWord(word string) (string, bool) {
+	if db == nil {
+		return "", false
+	}
+	return db.speller.Correct(word)
+}
+
+func (db *searcherDB) Suggest(prefix string, n int) []string {
+	if db == nil {
+		return nil
+	}
+	return db.suggester.Suggest(prefix, n)
+}
+
+func (db *searcherDB) BM25Stats() *gcse.BM25Stats {
+	if db == nil {
+		return &gcse.BM25Stats{}
+	}
+	return &db.bm25Stats
+}
+
+func (db *searcherDB) SimilarPackages(id string, n int) []SimilarPackage {
+	if db == nil {
+		return nil
+	}
+	similar := db.similar.get(id, func() []SimilarPackage {
+		hit, found := db.FindFullPackage(id)
+		if !found {
+			return nil
+		}
+		return findSimilarPackages(db, &hit, maxSimilarCount)
+	})
+	if len(similar) > n {
+		similar = similar[:n]
+	}
+	return similar
+}
+
+func (db *searcherDB) PackageFeed() *packageFeed {
+	if db == nil {
+		return nil
+	}
+	return db.feed
+}
+
+func (db *searcherDB) Sitemap() *siteMap {
+	if db == nil {
+		return nil
+	}
+	return db.sitemap
+}
+
 func getDatabase() database {
 	db, ok := databaseValue.Load().(database)
 	if !ok {
@@ -139,7 +250,7 @@ func loadIndex() error {
 		// no new index
 		return nil
 	}
-	db := &searcherDB{}
+	db := &searcherDB{segment: segm}
 	if err := func() error {
 		f, err := os.Open(segm.Join(gcse.IndexFn))
 		if err != nil {
@@ -161,21 +272,54 @@ func loadIndex() error {
 		log.Printf("OpenConstArray %v failed: %v", hitsPath, err)
 		return err
 	}
-	// Calculate db.projectCount
-	var projects stringsp.Set
+	indexUpdated, ok := segmentIndexTime(segm)
+	if !ok {
+		indexUpdated = time.Now()
+	}
+	db.indexUpdated = indexUpdated
+	// The feeds are of the packages since the previous index segment.
+	since, ok := previousIndexTime(segm)
+	if !ok {
+		since = db.indexUpdated.Add(-defaultFeedWindow)
+	}
+	db.feed = newPackageFeed(since, loadFoundTimes(db, since))
+	db.sitemap = newSiteMap(db.PackageCount(), sitemapShardSize)
+	// Calculate db.projectCount and db.bm25Stats, build the spelling
+	// vocabulary and the suggester, and fill the feeds and the sitemap.
+	var projects, seenWords stringsp.Set
+	db.speller = newSpellChecker()
+	suggestions := make(suggestBuilder)
 	db.ts.Search(nil, func(docID int32, data interface{}) error {
 		hit := data.(gcse.HitInfo)
 		projects.Add(hit.ProjectURL)
+		db.bm25Stats.Add(&hit)
+		db.feed.add(&hit)
+		db.sitemap.add(docID, &hit)
+		suggestions.add(hit.Name, hit.StaticScore)
+		suggestions.add(hit.Package, hit.StaticScore)
+
+		text := hit.Name + " " + hit.Package + " " + hit.Synopsis + " " + strings.Join(hit.ImportantSentences, " ")
+		for _, w := range spellWords(text) {
+			if !seenWords.Contain(w) {
+				seenWords.Add(w)
+				if cnt := db.PackageCountOfToken(gcse.IndexTextField, gcse.NormWord(w)); cnt >= minSpellWordCount {
+					db.speller.addWord(w, cnt)
+				}
+			}
+			if _, ok := db.speller.counts[w]; ok {
+				// A popular token is scored by its best package.
+				suggestions.add(w, hit.StaticScore)
+			}
+		}
 		return nil
 	})
+	db.feed.finish()
 	db.projectCount = len(projects)
+	db.suggester = suggestions.build()
+	log.Printf("%d words in the spelling vocabulary, %d suggestions", len(db.speller.counts), len(db.suggester.entries))
+	log.Printf("%d new and %d updated packages in the feeds since %v", len(db.feed.New), len(db.feed.Updated), since)
 	gcse.AddBiValueAndProcess(bi.Max, "index.proj-count", db.projectCount)
 
-	// Update db.indexUpdated
-	db.indexUpdated = time.Now()
-	if st, err := os.Stat(segm.Join(gcse.IndexFn)); err == nil {
-		db.indexUpdated = st.ModTime()
-	}
 	indexSegment = segm
 	log.Printf("Load index from %v (%d packages)", segm, db.PackageCount())
 
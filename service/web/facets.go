@@ -0,0 +1,158 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/golangplus/sort"
+
+	"github.com/daviddengcn/gcse"
+)
+
+// Maximum number of values shown for the site and author facets.
+const maxFacetValues = 10
+
+// FacetCount is the number of hits of a facet value.
+type FacetCount struct {
+	Value string `json:"value"`
+	// Filter is the qualifiers restricting the hits to this value, e.g.
+	// "site:github.com".
+	Filter string `json:"filter"`
+	Count  int    `json:"count"`
+	// Selected is true if the query already has the Filter.
+	Selected bool `json:"selected,omitempty"`
+	// Q is the query toggling the Filter, for the link of the value.
+	Q string `json:"-"`
+}
+
+// Facets are the numbers of hits grouped by site, author, kind and stars.
+type Facets struct {
+	Sites   []FacetCount `json:"sites"`
+	Authors []FacetCount `json:"authors"`
+	Kinds   []FacetCount `json:"kinds"`
+	Stars   []FacetCount `json:"stars"`
+}
+
+// starBuckets are the star ranges of the Stars facet.
+var starBuckets = []struct {
+	value  string
+	filter string
+	min    int
+}{
+	{"< 10", "stars:<10", 0},
+	{"10 - 99", "stars:>=10 stars:<100", 10},
+	{"100 - 999", "stars:>=100 stars:<1000", 100},
+	{">= 1000", "stars:>=1000", 1000},
+}
+
+// facetCounter counts the facets of hits.
+type facetCounter struct {
+	sites, authors map[string]int
+	commands       int
+	libraries      int
+	stars          []int // counts of starBuckets
+}
+
+func newFacetCounter() *facetCounter {
+	return &facetCounter{
+		sites:   make(map[string]int),
+		authors: make(map[string]int),
+		stars:   make([]int, len(starBuckets)),
+	}
+}
+
+func (fc *facetCounter) add(hit *gcse.HitInfo) {
+	if site := strings.ToLower(gcse.HostOfPackage(hit.Package)); site != "" {
+		fc.sites[site]++
+	}
+	if author := gcse.AuthorOfPackage(hit.Package); author != "" {
+		fc.authors[author]++
+	}
+	if hit.Name == "main" {
+		fc.commands++
+	} else {
+		fc.libraries++
+	}
+	for i := len(starBuckets) - 1; i >= 0; i-- {
+		if hit.StarCount >= starBuckets[i].min {
+			fc.stars[i]++
+			break
+		}
+	}
+}
+
+// topFacetCounts returns the values of counts with the most hits.
+func topFacetCounts(counts map[string]int, qualifier string) []FacetCount {
+	res := make([]FacetCount, 0, len(counts))
+	for v, cnt := range counts {
+		res = append(res, FacetCount{Value: v, Filter: qualifier + v, Count: cnt})
+	}
+	sortp.SortF(len(res), func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Value < res[j].Value
+	}, func(i, j int) {
+		res[i], res[j] = res[j], res[i]
+	})
+	if len(res) > maxFacetValues {
+		res = res[:maxFacetValues]
+	}
+	return res
+}
+
+// facets returns the counted facets, with the Q of each value toggling its
+// filter in q.
+func (fc *facetCounter) facets(q string) *Facets {
+	f := &Facets{
+		Sites:   topFacetCounts(fc.sites, "site:"),
+		Authors: topFacetCounts(fc.authors, "author:"),
+	}
+	if fc.commands > 0 {
+		f.Kinds = append(f.Kinds, FacetCount{Value: "command", Filter: "kind:command", Count: fc.commands})
+	}
+	if fc.libraries > 0 {
+		f.Kinds = append(f.Kinds, FacetCount{Value: "library", Filter: "kind:library", Count: fc.libraries})
+	}
+	for i, b := range starBuckets {
+		if fc.stars[i] > 0 {
+			f.Stars = append(f.Stars, FacetCount{Value: b.value, Filter: b.filter, Count: fc.stars[i]})
+		}
+	}
+	for _, counts := range [][]FacetCount{f.Sites, f.Authors, f.Kinds, f.Stars} {
+		for i := range counts {
+			counts[i].Q, counts[i].Selected = toggleFilter(q, counts[i].Filter)
+		}
+	}
+	return f
+}
+
+// toggleFilter removes the terms of filter from q if q has all of them, or
+// appends them otherwise. It returns the new query and whether q has filter.
+// Qualifiers are compared case-insensitively.
+func toggleFilter(q, filter string) (string, bool) {
+	terms, filterTerms := splitQuery(q), splitQuery(filter)
+	has := func(t queryTerm) bool {
+		for _, ft := range filterTerms {
+			if t == ft || !t.quoted && !ft.quoted && t.negated == ft.negated && strings.EqualFold(t.text, ft.text) {
+				return true
+			}
+		}
+		return false
+	}
+	cnt := 0
+	for _, t := range terms {
+		if has(t) {
+			cnt++
+		}
+	}
+	if cnt < len(filterTerms) {
+		return strings.TrimSpace(q + " " + filter), false
+	}
+	var rest []queryTerm
+	for _, t := range terms {
+		if !has(t) {
+			rest = append(rest, t)
+		}
+	}
+	return joinQuery(rest), true
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestFacetCounter(t *testing.T) {
+	fc := newFacetCounter()
+	for _, info := range []gcse.DocInfo{
+		{Name: "cobra", Package: "github.com/spf13/cobra", StarCount: 1200},
+		{Name: "main", Package: "github.com/spf13/cobra/cobra", StarCount: 50},
+		{Name: "viper", Package: "github.com/spf13/viper", StarCount: 5},
+		{Name: "yaml", Package: "gopkg.in/yaml.v2", StarCount: 5},
+	} {
+		fc.add(&gcse.HitInfo{DocInfo: info})
+	}
+	f := fc.facets("config site:github.com")
+	assert.Equal(t, "f.Sites", f.Sites, []FacetCount{
+		{Value: "github.com", Filter: "site:github.com", Count: 3, Selected: true, Q: "config"},
+		{Value: "gopkg.in", Filter: "site:gopkg.in", Count: 1, Q: "config site:github.com site:gopkg.in"},
+	})
+	assert.Equal(t, "f.Authors[0].Value", f.Authors[0].Value, "spf13")
+	assert.Equal(t, "f.Authors[0].Count", f.Authors[0].Count, 3)
+	assert.Equal(t, "f.Kinds", f.Kinds, []FacetCount{
+		{Value: "command", Filter: "kind:command", Count: 1, Q: "config site:github.com kind:command"},
+		{Value: "library", Filter: "kind:library", Count: 3, Q: "config site:github.com kind:library"},
+	})
+	assert.Equal(t, "len(f.Stars)", len(f.Stars), 3)
+	assert.Equal(t, "f.Stars[0].Count", f.Stars[0].Count, 2)
+	assert.Equal(t, "f.Stars[1].Filter", f.Stars[1].Filter, "stars:>=10 stars:<100")
+}
+
+func TestToggleFilter(t *testing.T) {
+	q, selected := toggleFilter("http", "stars:>=10 stars:<100")
+	assert.Equal(t, "q", q, "http stars:>=10 stars:<100")
+	assert.False(t, "selected", selected)
+
+	q, selected = toggleFilter(q, "stars:>=10 stars:<100")
+	assert.Equal(t, "q", q, "http")
+	assert.True(t, "selected", selected)
+
+	q, selected = toggleFilter(`"web framework" Site:GitHub.com`, "site:github.com")
+	assert.Equal(t, "q", q, `"web framework"`)
+	assert.True(t, "selected", selected)
+}
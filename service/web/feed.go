@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golangplus/sort"
+	"golang.org/x/net/trace"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/gcse/configs"
+	"github.com/daviddengcn/gcse/store"
+	"github.com/daviddengcn/gcse/utils"
+	"github.com/daviddengcn/go-easybi"
+
+	gpb "github.com/daviddengcn/gcse/shared/proto"
+)
+
+const (
+	feedPath = "/feed/"
+
+	maxFeedEntries = 100
+	// Number of search hits checked for /feed/search.
+	maxFeedSearchHits = 1000
+	// The packages of this period before the index are in the feeds if the
+	// previous index segment is gone.
+	defaultFeedWindow = 7 * 24 * time.Hour
+)
+
+// feedItem is a package in a feed, new or updated at Time.
+type feedItem struct {
+	Package  string
+	Name     string
+	Synopsis string
+	Time     time.Time
+}
+
+// packageFeed is the packages first seen, or updated, since the previous
+// index segment.
+type packageFeed struct {
+	Since time.Time
+	// The latest first, at most maxFeedEntries.
+	New     []feedItem
+	Updated []feedItem
+
+	// Found times of packages first seen since Since.
+	foundTimes map[string]time.Time
+}
+
+func newPackageFeed(since time.Time, foundTimes map[string]time.Time) *packageFeed {
+	return &packageFeed{Since: since, foundTimes: foundTimes}
+}
+
+func toFeedItem(hit *gcse.HitInfo, t time.Time) feedItem {
+	return feedItem{
+		Package:  hit.Package,
+		Name:     hit.Name,
+		Synopsis: strings.TrimSpace(hit.Synopsis),
+		Time:     t,
+	}
+}
+
+// item returns the feed item of hit if it is new or updated since f.Since.
+func (f *packageFeed) item(hit *gcse.HitInfo) (feedItem, bool) {
+	if t, ok := f.foundTimes[hit.Package]; ok {
+		return toFeedItem(hit, t), true
+	}
+	if hit.LastUpdated.After(f.Since) {
+		return toFeedItem(hit, hit.LastUpdated), true
+	}
+	return feedItem{}, false
+}
+
+// add adds hit to New or Updated if it is new or updated since f.Since.
+func (f *packageFeed) add(hit *gcse.HitInfo) {
+	if t, ok := f.foundTimes[hit.Package]; ok {
+		f.New = append(f.New, toFeedItem(hit, t))
+	} else if hit.LastUpdated.After(f.Since) {
+		f.Updated = append(f.Updated, toFeedItem(hit, hit.LastUpdated))
+	}
+}
+
+// sortFeedItems sorts items with the latest first and returns at most
+// maxFeedEntries of them.
+func sortFeedItems(items []feedItem) []feedItem {
+	sortp.SortF(len(items), func(i, j int) bool {
+		if !items[i].Time.Equal(items[j].Time) {
+			return items[i].Time.After(items[j].Time)
+		}
+		return items[i].Package < items[j].Package
+	}, func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+	if len(items) > maxFeedEntries {
+		items = items[:maxFeedEntries]
+	}
+	return items
+}
+
+// finish is called after all packages are added.
+func (f *packageFeed) finish() {
+	f.New = sortFeedItems(f.New)
+	f.Updated = sortFeedItems(f.Updated)
+}
+
+// previousIndexTime returns the time the index segment before segm was
+// built, or false if it is gone.
+func previousIndexTime(segm utils.Segment) (time.Time, bool) {
+	dones, err := configs.IndexSegments().ListDones()
+	if err != nil {
+		return time.Time{}, false
+	}
+	var prev utils.Segment
+	for _, s := range dones {
+		if utils.SegmentLess(s, segm) && (prev == "" || utils.SegmentLess(prev, s)) {
+			prev = s
+		}
+	}
+	if prev == "" {
+		return time.Time{}, false
+	}
+	return segmentIndexTime(prev)
+}
+
+func segmentIndexTime(segm utils.Segment) (time.Time, bool) {
+	st, err := os.Stat(segm.Join(gcse.IndexFn))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return st.ModTime(), true
+}
+
+// loadFoundTimes returns the found times, in the store snapshot of the index,
+// of the packages first seen after since.
+func loadFoundTimes(db *searcherDB, since time.Time) map[string]time.Time {
+	foundTimes := make(map[string]time.Time)
+	if err := store.ForEachPackageHistoryOf(db.storeDB, func(site, path string, info *gpb.HistoryInfo) error {
+		if info.FoundTime == nil {
+			return nil
+		}
+		if t, err := ptypes.Timestamp(info.FoundTime); err == nil && t.After(since) {
+			foundTimes[site+"/"+path] = t
+		}
+		return nil
+	}); err != nil {
+		log.Printf("ForEachPackageHistoryOf failed: %v", err)
+	}
+	return foundTimes
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Link    atomLink `xml:"link"`
+	Updated string   `xml:"updated"`
+	Summary string   `xml:"summary,omitempty"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Link    []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description,omitempty"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate"`
+}
+
+type rssFeed struct {
+	XMLName     xml.Name  `xml:"rss"`
+	Version     string    `xml:"version,attr"`
+	Title       string    `xml:"channel>title"`
+	Link        string    `xml:"channel>link"`
+	Description string    `xml:"channel>description"`
+	Items       []rssItem `xml:"channel>item"`
+}
+
+// writeFeed writes items as an Atom feed, or RSS 2.0 if format is "rss".
+// selfURL is the URL of the feed, and siteURL is prefixed to the view pages.
+func writeFeed(w http.ResponseWriter, format, title, selfURL, siteURL string, updated time.Time, items []feedItem) error {
+	var feed interface{}
+	if format == "rss" {
+		w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+		rss := &rssFeed{Version: "2.0", Title: title, Link: selfURL, Description: title}
+		for _, item := range items {
+			link := siteURL + "/view?id=" + url.QueryEscape(item.Package)
+			rss.Items = append(rss.Items, rssItem{
+				Title:       item.Package,
+				Link:        link,
+				Description: item.Synopsis,
+				GUID:        fmt.Sprintf("%s#%d", link, item.Time.Unix()),
+				PubDate:     item.Time.UTC().Format(time.RFC1123Z),
+			})
+		}
+		feed = rss
+	} else {
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		atom := &atomFeed{
+			Title:   title,
+			ID:      selfURL,
+			Link:    []atomLink{{Href: selfURL, Rel: "self"}},
+			Updated: updated.UTC().Format(time.RFC3339),
+		}
+		for _, item := range items {
+			link := siteURL + "/view?id=" + url.QueryEscape(item.Package)
+			atom.Entries = append(atom.Entries, atomEntry{
+				Title:   item.Package,
+				ID:      fmt.Sprintf("%s#%d", link, item.Time.Unix()),
+				Link:    atomLink{Href: link},
+				Updated: item.Time.UTC().Format(time.RFC3339),
+				Summary: item.Synopsis,
+			})
+		}
+		feed = atom
+	}
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(feed)
+}
+
+// pageFeed serves the feeds of packages since the previous index segment:
+//
+//	/feed/new             packages first seen
+//	/feed/updated         packages updated
+//	/feed/search?q=...    packages of the search results first seen or updated
+//
+// The feeds are in Atom, or RSS 2.0 if format=rss.
+func pageFeed(w http.ResponseWriter, r *http.Request) {
+	tr := trace.New("pageFeed", r.URL.Path)
+	defer tr.Finish()
+
+	db := getDatabase()
+	feed := db.PackageFeed()
+	if feed == nil {
+		// Index not loaded, nothing is new.
+		feed = newPackageFeed(time.Now(), nil)
+	}
+	siteURL := configs.SiteURL
+	selfURL := siteURL + r.URL.RequestURI()
+	var title string
+	var items []feedItem
+	switch strings.TrimPrefix(r.URL.Path, feedPath) {
+	case "new":
+		bi.Inc("feed.new")
+		title, items = "New Go packages", feed.New
+	case "updated":
+		bi.Inc("feed.updated")
+		title, items = "Updated Go packages", feed.Updated
+	case "search":
+		bi.Inc("feed.search")
+		q := strings.TrimSpace(r.FormValue("q"))
+		if q == "" {
+			http.Error(w, "Missing parameter: q", http.StatusBadRequest)
+			return
+		}
+		results, _, err := search(tr, db, q, searchOptions{Limit: maxFeedSearchHits})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for _, hit := range results.Hits {
+			if item, ok := feed.item(&hit.HitInfo); ok {
+				items = append(items, item)
+			}
+		}
+		title, items = fmt.Sprintf("New and updated Go packages for %q", q), sortFeedItems(items)
+	default:
+		pageNotFound(w, r)
+		return
+	}
+	if err := writeFeed(w, r.FormValue("format"), title, selfURL, siteURL, db.IndexUpdated(), items); err != nil {
+		tr.LazyPrintf("writeFeed failed: %v", err)
+	}
+}
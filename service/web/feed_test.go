@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestPackageFeed(t *testing.T) {
+	since := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	newHit := func(pkg string, updated time.Time) gcse.HitInfo {
+		return gcse.HitInfo{DocInfo: gcse.DocInfo{Package: pkg, LastUpdated: updated}}
+	}
+	f := newPackageFeed(since, map[string]time.Time{
+		"a/new":   since.Add(time.Hour),
+		"b/newer": since.Add(2 * time.Hour),
+	})
+	for _, hit := range []gcse.HitInfo{
+		newHit("a/new", since.Add(-time.Hour)),
+		newHit("b/newer", since.Add(3*time.Hour)),
+		newHit("c/updated", since.Add(time.Hour)),
+		newHit("d/old", since.Add(-time.Hour)),
+	} {
+		f.add(&hit)
+	}
+	f.finish()
+	assert.Equal(t, "New", f.New, []feedItem{
+		{Package: "b/newer", Time: since.Add(2 * time.Hour)},
+		{Package: "a/new", Time: since.Add(time.Hour)},
+	})
+	assert.Equal(t, "Updated", f.Updated, []feedItem{
+		{Package: "c/updated", Time: since.Add(time.Hour)},
+	})
+
+	hit := newHit("d/old", since.Add(-time.Hour))
+	_, ok := f.item(&hit)
+	assert.False(t, "ok", ok)
+}
+
+func TestWriteFeed(t *testing.T) {
+	tm := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	items := []feedItem{{Package: "github.com/a/b", Synopsis: "syn", Time: tm}}
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, writeFeed(w, "", "New", "http://h/feed/new", "http://h", tm, items))
+	assert.Equal(t, "Content-Type", w.Header().Get("Content-Type"), "application/atom+xml; charset=utf-8")
+	var atom atomFeed
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &atom))
+	assert.Equal(t, "Entries", atom.Entries, []atomEntry{{
+		Title:   "github.com/a/b",
+		ID:      "http://h/view?id=github.com%2Fa%2Fb#1483228800",
+		Link:    atomLink{Href: "http://h/view?id=github.com%2Fa%2Fb"},
+		Updated: "2017-01-01T00:00:00Z",
+		Summary: "syn",
+	}})
+
+	w = httptest.NewRecorder()
+	assert.NoError(t, writeFeed(w, "rss", "New", "http://h/feed/new", "http://h", tm, items))
+	var rss rssFeed
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &rss))
+	assert.Equal(t, "Title", rss.Title, "New")
+	assert.Equal(t, "Items", rss.Items, []rssItem{{
+		Title:       "github.com/a/b",
+		Link:        "http://h/view?id=github.com%2Fa%2Fb",
+		Description: "syn",
+		GUID:        "http://h/view?id=github.com%2Fa%2Fb#1483228800",
+		PubDate:     "Sun, 01 Jan 2017 00:00:00 +0000",
+	}})
+}
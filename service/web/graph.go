@@ -0,0 +1,44 @@
+package main
+
+import "github.com/daviddengcn/gcse"
+
+var graphContentTypes = map[string]string{
+	gcse.GraphFormatDOT:     "text/vnd.graphviz; charset=utf-8",
+	gcse.GraphFormatGraphML: "application/graphml+xml; charset=utf-8",
+	gcse.GraphFormatJSON:    "application/json; charset=utf-8",
+}
+
+// buildImportGraph adds to g the imports, directly or not, of the package of
+// id, or of the packages of project if id is empty, read from the in-memory
+// hits. Returns false if the package or project is not found.
+func buildImportGraph(db database, g *gcse.ImportGraph, id, project string) (bool, error) {
+	find := func(pkg string) (imports, testImports []string, found bool) {
+		docs := db.TokenDocs(gcse.IndexPkgField, pkg)
+		if len(docs) == 0 {
+			return nil, nil, false
+		}
+		hit, found := db.PackageOfDoc(docs[0])
+		return hit.Imports, hit.TestImports, found
+	}
+	if id != "" {
+		if _, _, found := find(id); !found {
+			return false, nil
+		}
+		g.AddImportClosure([]string{id}, find)
+		return true, nil
+	}
+	var roots []string
+	if err := db.Search(nil, func(_ int32, data interface{}) error {
+		if pkg := data.(gcse.HitInfo).Package; gcse.FullProjectOfPackage(pkg) == project {
+			roots = append(roots, pkg)
+		}
+		return nil
+	}); err != nil {
+		return false, err
+	}
+	if len(roots) == 0 {
+		return false, nil
+	}
+	g.AddImportClosure(roots, find)
+	return true, nil
+}
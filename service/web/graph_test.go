@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestBuildImportGraph(t *testing.T) {
+	newHit := func(pkg string, imports ...string) gcse.HitInfo {
+		return gcse.HitInfo{DocInfo: gcse.DocInfo{Package: pkg, Imports: imports}}
+	}
+	db := hitsDB{hits: []gcse.HitInfo{
+		newHit("github.com/a/x", "github.com/b/y"),
+		newHit("github.com/a/x/w", "fmt"),
+		newHit("github.com/b/y", "github.com/c/z"),
+		newHit("github.com/c/z"),
+		newHit("github.com/d/v", "github.com/a/x"),
+	}}
+
+	g := &gcse.ImportGraph{}
+	found, err := buildImportGraph(db, g, "github.com/a/x", "")
+	assert.NoError(t, err)
+	assert.True(t, "found", found)
+	assert.Equal(t, "Nodes", g.Nodes(), []string{"github.com/a/x", "github.com/b/y", "github.com/c/z"})
+
+	g = &gcse.ImportGraph{}
+	found, err = buildImportGraph(db, g, "", "github.com/a/x")
+	assert.NoError(t, err)
+	assert.True(t, "found", found)
+	assert.Equal(t, "Nodes", g.Nodes(), []string{"fmt", "github.com/a/x", "github.com/a/x/w", "github.com/b/y", "github.com/c/z"})
+
+	found, _ = buildImportGraph(db, &gcse.ImportGraph{}, "github.com/e/u", "")
+	assert.False(t, "found", found)
+	found, _ = buildImportGraph(db, &gcse.ImportGraph{}, "", "github.com/e/u")
+	assert.False(t, "found", found)
+}
+
+func TestPageApi_graph(t *testing.T) {
+	w := httptest.NewRecorder()
+	pageApi(w, httptest.NewRequest("GET", "/api?action=graph", nil))
+	assert.Equal(t, "code", w.Code, http.StatusBadRequest)
+}
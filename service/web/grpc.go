@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+
+	"golang.org/x/net/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/go-easybi"
+
+	gpb "github.com/daviddengcn/gcse/shared/proto"
+)
+
+const (
+	defaultGrpcTops = 20
+	maxGrpcTops     = 100
+)
+
+// searchServer serves the gRPC SearchService against the database returned by
+// db, which is getDatabase except in tests.
+type searchServer struct {
+	db func() database
+}
+
+var _ gpb.SearchServiceServer = (*searchServer)(nil)
+
+func (s *searchServer) Search(_ context.Context, req *gpb.SearchReq) (*gpb.SearchResp, error) {
+	bi.Inc("grpc.search")
+	q := strings.TrimSpace(req.Query)
+	if q == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "empty query")
+	}
+	limit := int(req.Limit)
+	if limit <= 0 || limit > MAX_API_SEARCH_HITS {
+		limit = MAX_API_SEARCH_HITS
+	}
+	tr := trace.New("SearchService.Search", q)
+	defer tr.Finish()
+	results, _, err := search(tr, s.db(), q, searchOptions{Limit: limit, Scorer: req.Scorer})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "search %q failed: %v", q, err)
+	}
+	resp := &gpb.SearchResp{
+		TotalResults: int32(results.TotalResults),
+		Corrected:    results.Corrected,
+	}
+	for _, hit := range results.Hits {
+		h := &gpb.SearchHit{
+			Package:    hit.Package,
+			Name:       hit.Name,
+			Author:     hit.Author,
+			Synopsis:   hit.Synopsis,
+			ProjectUrl: hit.ProjectURL,
+			Stars:      int32(hit.StarCount),
+			Score:      hit.Score,
+			MatchScore: hit.MatchScore,
+			Symbols:    hit.Symbols,
+		}
+		for _, fork := range hit.Forks {
+			h.Forks = append(h.Forks, fork.Package)
+		}
+		resp.Hits = append(resp.Hits, h)
+	}
+	return resp, nil
+}
+
+func (s *searchServer) findPackage(id string) (gcse.HitInfo, error) {
+	hit, found := s.db().FindFullPackage(id)
+	if !found {
+		return hit, status.Errorf(codes.NotFound, "package %s not found", id)
+	}
+	return hit, nil
+}
+
+func (s *searchServer) GetPackage(_ context.Context, req *gpb.GetPackageReq) (*gpb.GetPackageResp, error) {
+	bi.Inc("grpc.getpackage")
+	hit, err := s.findPackage(req.Package)
+	if err != nil {
+		return nil, err
+	}
+	return &gpb.GetPackageResp{Package: &gpb.IndexedPackage{
+		Package:     hit.Package,
+		Name:        hit.Name,
+		Synopsis:    hit.Synopsis,
+		Description: hit.Description,
+		Author:      hit.Author,
+		ProjectUrl:  hit.ProjectURL,
+		Stars:       int32(hit.StarCount),
+		StaticRank:  int32(hit.StaticRank + 1),
+		ForkOf:      hit.ForkOf,
+		Imports:     hit.Imports,
+		TestImports: hit.TestImports,
+	}}, nil
+}
+
+func (s *searchServer) ListImporters(_ context.Context, req *gpb.ListImportersReq) (*gpb.ListImportersResp, error) {
+	bi.Inc("grpc.listimporters")
+	hit, err := s.findPackage(req.Package)
+	if err != nil {
+		return nil, err
+	}
+	return &gpb.ListImportersResp{
+		Importers:     hit.Imported,
+		TestImporters: hit.TestImported,
+	}, nil
+}
+
+func (s *searchServer) Tops(_ context.Context, req *gpb.TopsReq) (*gpb.TopsResp, error) {
+	bi.Inc("grpc.tops")
+	n := int(req.Count)
+	if n <= 0 {
+		n = defaultGrpcTops
+	} else if n > maxGrpcTops {
+		n = maxGrpcTops
+	}
+	resp := &gpb.TopsResp{}
+	for _, l := range statTops(n) {
+		list := &gpb.TopList{Name: l.Name, Info: l.Info}
+		for _, item := range l.Items {
+			list.Items = append(list.Items, &gpb.TopList_Item{
+				Name:    item.Name,
+				Package: item.Package,
+				Link:    item.Link,
+				Info:    item.Info,
+			})
+		}
+		resp.Lists = append(resp.Lists, list)
+	}
+	return resp, nil
+}
+
+// serveSearchGrpc serves the gRPC SearchService at addr.
+func serveSearchGrpc(addr string) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Fatalf("Listening to %s failed: %v", addr, err)
+	}
+	grpcServer := grpc.NewServer()
+	gpb.RegisterSearchServiceServer(grpcServer, &searchServer{db: getDatabase})
+	log.Printf("Serving SearchService at %s ...", addr)
+	log.Fatal(grpcServer.Serve(lis))
+}
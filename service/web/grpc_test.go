@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daviddengcn/gcse"
+
+	gpb "github.com/daviddengcn/gcse/shared/proto"
+)
+
+func TestSearchServer_Packages(t *testing.T) {
+	db := hitsDB{hits: []gcse.HitInfo{{
+		DocInfo: gcse.DocInfo{
+			Package: "a/x",
+			Name:    "x",
+			Imports: []string{"b/y"},
+		},
+		Imported:     []string{"c/z"},
+		TestImported: []string{"d/w"},
+		StaticRank:   2,
+	}}}
+	s := &searchServer{db: func() database { return db }}
+
+	resp, err := s.GetPackage(context.Background(), &gpb.GetPackageReq{Package: "a/x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Package", *resp.Package, gpb.IndexedPackage{
+		Package:    "a/x",
+		Name:       "x",
+		StaticRank: 3,
+		Imports:    []string{"b/y"},
+	})
+
+	importers, err := s.ListImporters(context.Background(), &gpb.ListImportersReq{Package: "a/x"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Importers", importers.Importers, []string{"c/z"})
+	assert.Equal(t, "TestImporters", importers.TestImporters, []string{"d/w"})
+
+	_, err = s.GetPackage(context.Background(), &gpb.GetPackageReq{Package: "b/y"})
+	assert.Equal(t, "code", status.Code(err), codes.NotFound)
+	_, err = s.ListImporters(context.Background(), &gpb.ListImportersReq{Package: "b/y"})
+	assert.Equal(t, "code", status.Code(err), codes.NotFound)
+}
+
+func TestSearchServer_Search(t *testing.T) {
+	newHit := func(pkg, name, synopsis string, staticScore float64) gcse.HitInfo {
+		return gcse.HitInfo{
+			DocInfo:     gcse.DocInfo{Package: pkg, Name: name, Synopsis: synopsis},
+			StaticScore: staticScore,
+		}
+	}
+	db := hitsDB{hits: []gcse.HitInfo{
+		newHit("a/yaml", "yaml", "YAML support", 3),
+		newHit("b/yaml", "yaml", "another YAML support", 5),
+		newHit("c/toml", "toml", "TOML parser", 9),
+		newHit("d/yaml", "yaml", "YAML library", 1),
+	}}
+	s := &searchServer{db: func() database { return db }}
+	pkgsOf := func(resp *gpb.SearchResp) []string {
+		var pkgs []string
+		for _, hit := range resp.Hits {
+			pkgs = append(pkgs, hit.Package)
+		}
+		return pkgs
+	}
+
+	resp, err := s.Search(context.Background(), &gpb.SearchReq{Query: "yaml"})
+	assert.NoError(t, err)
+	assert.Equal(t, "TotalResults", resp.TotalResults, int32(3))
+	assert.Equal(t, "pkgs", pkgsOf(resp), []string{"b/yaml", "a/yaml", "d/yaml"})
+	assert.Equal(t, "Synopsis", resp.Hits[0].Synopsis, "another YAML support")
+
+	resp, err = s.Search(context.Background(), &gpb.SearchReq{Query: "yaml", Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "TotalResults", resp.TotalResults, int32(3))
+	assert.Equal(t, "pkgs", pkgsOf(resp), []string{"b/yaml", "a/yaml"})
+
+	// Out of range limits fall back to the maximum.
+	resp, err = s.Search(context.Background(), &gpb.SearchReq{Query: "yaml", Limit: -1})
+	assert.NoError(t, err)
+	assert.Equal(t, "len(Hits)", len(resp.Hits), 3)
+
+	_, err = s.Search(context.Background(), &gpb.SearchReq{Query: " "})
+	assert.Equal(t, "code", status.Code(err), codes.InvalidArgument)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/daviddengcn/gcse"
+)
+
+const (
+	defaultImportersDepth = 3
+	maxImportersDepth     = 5
+	// The depth of the importers tab of the view page.
+	viewImportersDepth = 2
+	// The search stops after finding this many importers.
+	maxTransitiveImporters = 1000
+)
+
+// ImporterLevel is the importers of a package at a depth, i.e. importing it
+// through Depth-1 other packages.
+type ImporterLevel struct {
+	Depth    int      `json:"depth"`
+	Count    int      `json:"count"`
+	Packages []string `json:"packages"`
+}
+
+// TransitiveImporters is the packages depending on a package, directly or
+// not.
+type TransitiveImporters struct {
+	Package string `json:"package"`
+	// Depth is the maximum depth searched.
+	Depth int `json:"depth"`
+	Total int `json:"total"`
+	// Truncated is set if the search stopped at maxTransitiveImporters.
+	Truncated bool            `json:"truncated,omitempty"`
+	Levels    []ImporterLevel `json:"levels"`
+}
+
+// findTransitiveImporters returns the importers of the package of id up to
+// depth levels, searched breadth first over the in-memory imports fields.
+// Each importer is only returned at the lowest depth, so cycles are cut. Test
+// importers are included if tests is true. Returns false if the package is not
+// found.
+func findTransitiveImporters(db database, id string, depth int, tests bool) (*TransitiveImporters, bool) {
+	docs := db.TokenDocs(gcse.IndexPkgField, id)
+	if len(docs) == 0 {
+		return nil, false
+	}
+	res := &TransitiveImporters{Package: id, Depth: depth, Levels: []ImporterLevel{}}
+	visited := map[int32]bool{docs[0]: true}
+	frontier := []string{id}
+	for d := 1; d <= depth && len(frontier) > 0 && !res.Truncated; d++ {
+		var next []string
+		for _, pkg := range frontier {
+			importers := db.TokenDocs(gcse.IndexImportsField, pkg)
+			if tests {
+				importers = append(importers[:len(importers):len(importers)], db.TokenDocs(gcse.IndexTestImportsField, pkg)...)
+			}
+			for _, docID := range importers {
+				if visited[docID] {
+					continue
+				}
+				if res.Total >= maxTransitiveImporters {
+					res.Truncated = true
+					break
+				}
+				visited[docID] = true
+				importer, found := db.PackageOfDoc(docID)
+				if !found {
+					continue
+				}
+				next = append(next, importer.Package)
+				res.Total++
+			}
+			if res.Truncated {
+				break
+			}
+		}
+		if len(next) == 0 {
+			break
+		}
+		sort.Strings(next)
+		res.Levels = append(res.Levels, ImporterLevel{Depth: d, Count: len(next), Packages: next})
+		frontier = next
+	}
+	return res, true
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestFindTransitiveImporters(t *testing.T) {
+	newHit := func(pkg string, imports, testImports []string) gcse.HitInfo {
+		return gcse.HitInfo{DocInfo: gcse.DocInfo{Package: pkg, Imports: imports, TestImports: testImports}}
+	}
+	db := hitsDB{hits: []gcse.HitInfo{
+		newHit("a", []string{"c"}, nil),
+		newHit("b", []string{"a"}, nil),
+		// c and d import each other.
+		newHit("c", []string{"a", "d"}, nil),
+		newHit("d", []string{"b", "c"}, nil),
+		newHit("e", []string{"d"}, nil),
+		newHit("t", nil, []string{"a"}),
+	}}
+	res, found := findTransitiveImporters(db, "a", 10, false)
+	assert.True(t, "found", found)
+	assert.Equal(t, "res", *res, TransitiveImporters{
+		Package: "a",
+		Depth:   10,
+		Total:   4,
+		Levels: []ImporterLevel{
+			{Depth: 1, Count: 2, Packages: []string{"b", "c"}},
+			{Depth: 2, Count: 1, Packages: []string{"d"}},
+			{Depth: 3, Count: 1, Packages: []string{"e"}},
+		},
+	})
+
+	res, _ = findTransitiveImporters(db, "a", 1, true)
+	assert.Equal(t, "Levels", res.Levels, []ImporterLevel{
+		{Depth: 1, Count: 3, Packages: []string{"b", "c", "t"}},
+	})
+
+	_, found = findTransitiveImporters(db, "x", 1, false)
+	assert.False(t, "found", found)
+}
@@ -0,0 +1,38 @@
+// Suggests package names, import paths and popular tokens for the search
+// boxes, each of which gets its own datalist.
+document.addEventListener('DOMContentLoaded', function() {
+	var inputs = document.querySelectorAll('.schblock input[name=q]');
+	Array.prototype.forEach.call(inputs, function(input, i) {
+		var list = document.createElement('datalist');
+		list.id = 'schsuggest' + i;
+		input.parentNode.insertBefore(list, input.nextSibling);
+		input.setAttribute('list', list.id);
+
+		var last = '';
+		input.addEventListener('input', function() {
+			var q = input.value;
+			if (q === last || q.indexOf(' ') >= 0) {
+				return;
+			}
+			last = q;
+			if (q === '') {
+				list.innerHTML = '';
+				return;
+			}
+			var xhr = new XMLHttpRequest();
+			xhr.onload = function() {
+				if (xhr.status !== 200 || input.value !== q) {
+					return;
+				}
+				list.innerHTML = '';
+				JSON.parse(xhr.responseText).suggestions.forEach(function(s) {
+					var opt = document.createElement('option');
+					opt.value = s;
+					list.appendChild(opt);
+				});
+			};
+			xhr.open('GET', '/api?action=suggest&q=' + encodeURIComponent(q));
+			xhr.send();
+		});
+	});
+});
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"golang.org/x/net/trace"
+
+	"github.com/daviddengcn/gcse/configs"
+	"github.com/daviddengcn/go-easybi"
+)
+
+const opensearchPath = "/opensearch.xml"
+
+type opensearchURL struct {
+	Type     string `xml:"type,attr"`
+	Rel      string `xml:"rel,attr,omitempty"`
+	Template string `xml:"template,attr"`
+}
+
+type opensearchImage struct {
+	Width  int    `xml:"width,attr"`
+	Height int    `xml:"height,attr"`
+	Type   string `xml:"type,attr"`
+	URL    string `xml:",chardata"`
+}
+
+type opensearchDescription struct {
+	XMLName       xml.Name        `xml:"http://a9.com/-/spec/opensearch/1.1/ OpenSearchDescription"`
+	ShortName     string          `xml:"ShortName"`
+	Description   string          `xml:"Description"`
+	InputEncoding string          `xml:"InputEncoding"`
+	Image         opensearchImage `xml:"Image"`
+	URLs          []opensearchURL `xml:"Url"`
+}
+
+// newOpensearchDescription returns the OpenSearch description of the search
+// of the index of packageCount packages. siteURL is prefixed to the URLs.
+func newOpensearchDescription(siteURL string, packageCount int) *opensearchDescription {
+	return &opensearchDescription{
+		ShortName:     "Go Search",
+		Description:   fmt.Sprintf("Search %d Go packages", packageCount),
+		InputEncoding: "UTF-8",
+		Image: opensearchImage{
+			Width: 16, Height: 16, Type: "image/png",
+			URL: siteURL + "/images/logo-16.png",
+		},
+		URLs: []opensearchURL{{
+			Type:     "text/html",
+			Template: siteURL + "/search?q={searchTerms}",
+		}, {
+			Type:     "application/x-suggestions+json",
+			Template: siteURL + "/api?action=suggest&format=opensearch&q={searchTerms}",
+		}, {
+			Type:     "application/opensearchdescription+xml",
+			Rel:      "self",
+			Template: siteURL + opensearchPath,
+		}},
+	}
+}
+
+// pageOpensearch serves the OpenSearch description, which lets browsers add
+// the search engine.
+func pageOpensearch(w http.ResponseWriter, r *http.Request) {
+	tr := trace.New("pageOpensearch", r.URL.Path)
+	defer tr.Finish()
+
+	bi.Inc("opensearch")
+	desc := newOpensearchDescription(configs.SiteURL, getDatabase().PackageCount())
+	w.Header().Set("Content-Type", "application/opensearchdescription+xml; charset=utf-8")
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(desc); err != nil {
+		tr.LazyPrintf("Encode failed: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+)
+
+func TestOpensearchDescription(t *testing.T) {
+	bs, err := xml.Marshal(newOpensearchDescription("http://h", 12))
+	assert.NoError(t, err)
+	var desc opensearchDescription
+	assert.NoError(t, xml.Unmarshal(bs, &desc))
+	assert.Equal(t, "Description", desc.Description, "Search 12 Go packages")
+	assert.Equal(t, "Image", desc.Image.URL, "http://h/images/logo-16.png")
+	assert.Equal(t, "URLs", desc.URLs, []opensearchURL{
+		{Type: "text/html", Template: "http://h/search?q={searchTerms}"},
+		{Type: "application/x-suggestions+json", Template: "http://h/api?action=suggest&format=opensearch&q={searchTerms}"},
+		{Type: "application/opensearchdescription+xml", Rel: "self", Template: "http://h/opensearch.xml"},
+	})
+}
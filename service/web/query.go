@@ -0,0 +1,386 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/golangplus/strings"
+
+	"github.com/daviddengcn/gcse"
+)
+
+// intFilter is a numeric condition like ">100" in "stars:>100".
+type intFilter struct {
+	op string
+	n  int
+}
+
+func parseIntFilter(s string) (intFilter, bool) {
+	op := "="
+	for _, o := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(s, o) {
+			op, s = o, s[len(o):]
+			break
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return intFilter{}, false
+	}
+	return intFilter{op: op, n: n}, true
+}
+
+func (f intFilter) match(v int) bool {
+	switch f.op {
+	case ">":
+		return v > f.n
+	case ">=":
+		return v >= f.n
+	case "<":
+		return v < f.n
+	case "<=":
+		return v <= f.n
+	}
+	return v == f.n
+}
+
+// Query is a parsed search query.
+//
+// Free words, "name:" words and "sym:" identifiers are searched in the index. Other qualifiers
+// are post-filters on gcse.HitInfo. Values of the same qualifier are ORed,
+// different qualifiers are ANDed.
+type Query struct {
+	Text stringsp.Set // tokens searched in gcse.IndexTextField
+	Name stringsp.Set // tokens searched in gcse.IndexNameField
+	// Words are the plain words, whose tokens are in Text, for expanding
+	// synonyms.
+	Words []string
+	// Syms are the "sym:" exported identifiers, like "NewRouter" or
+	// "Router.ServeHTTP", searched case-sensitively in
+	// gcse.IndexSymbolField.
+	Syms []string
+
+	Pkgs    []string // "pkg:", prefixes of the import path
+	Authors []string // "author:"
+	Sites   []string // "site:", hosts of the import path
+	Imports []string // "imports:", import paths of direct imports
+	Kinds   []string // "kind:", "command" or "library"
+	Stars   []intFilter
+
+	// Quoted phrases, as sequences of normalized words, which must (or,
+	// for NotPhrases, must not) appear adjacently in the package texts.
+	Phrases    [][]string
+	NotPhrases [][]string
+
+	// Excluded are the index queries of "-word" and "-name:word". A package
+	// matching any of them is excluded.
+	Excluded []map[string]stringsp.Set
+	// Not are the negated post-filters like "-author:spf13". A package
+	// matching any of them is excluded.
+	Not []*Query
+}
+
+// queryTerm is a space separated word or a quoted phrase of a query.
+type queryTerm struct {
+	text    string
+	negated bool
+	quoted  bool
+}
+
+// splitQuery splits q into terms. A leading '-' negates a term, a pair of
+// double quotes makes a phrase. An unclosed quote extends to the end.
+func splitQuery(q string) []queryTerm {
+	var terms []queryTerm
+	for {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			return terms
+		}
+		var term queryTerm
+		if q[0] == '-' {
+			term.negated = true
+			q = q[1:]
+		}
+		if strings.HasPrefix(q, `"`) {
+			term.quoted = true
+			q = q[1:]
+			p := strings.Index(q, `"`)
+			if p < 0 {
+				p = len(q)
+			}
+			term.text, q = q[:p], strings.TrimPrefix(q[p:], `"`)
+		} else {
+			p := strings.IndexFunc(q, unicode.IsSpace)
+			if p < 0 {
+				p = len(q)
+			}
+			term.text, q = q[:p], q[p:]
+		}
+		if strings.TrimSpace(term.text) == "" {
+			continue
+		}
+		terms = append(terms, term)
+	}
+}
+
+// joinQuery is the reverse of splitQuery.
+func joinQuery(terms []queryTerm) string {
+	strs := make([]string, 0, len(terms))
+	for _, t := range terms {
+		s := t.text
+		if t.quoted {
+			s = `"` + s + `"`
+		}
+		if t.negated {
+			s = "-" + s
+		}
+		strs = append(strs, s)
+	}
+	return strings.Join(strs, " ")
+}
+
+// parseQuery parses a query like `yaml -json "config file" author:go-yaml
+// stars:>100`. An unknown or malformed qualifier is kept as plain text.
+func parseQuery(q string) *Query {
+	query := &Query{}
+	for _, term := range splitQuery(q) {
+		switch {
+		case term.quoted && term.negated:
+			query.NotPhrases = append(query.NotPhrases, phraseWords(term.text))
+		case term.quoted:
+			query.Phrases = append(query.Phrases, phraseWords(term.text))
+			query.Text = gcse.AppendTokens(query.Text, []byte(term.text))
+		case term.negated:
+			not := &Query{}
+			if !not.addQualifier(term.text) {
+				not.Text = gcse.AppendTokens(nil, []byte(term.text))
+			}
+			switch {
+			case len(not.Text) > 0:
+				query.Excluded = append(query.Excluded, map[string]stringsp.Set{gcse.IndexTextField: not.Text})
+			case len(not.Name) > 0:
+				query.Excluded = append(query.Excluded, map[string]stringsp.Set{gcse.IndexNameField: not.Name})
+			case len(not.Syms) > 0:
+				query.Excluded = append(query.Excluded, map[string]stringsp.Set{gcse.IndexSymbolField: stringsp.NewSet(not.Syms...)})
+			case len(not.Pkgs)+len(not.Authors)+len(not.Sites)+len(not.Imports)+len(not.Kinds)+len(not.Stars) > 0:
+				query.Not = append(query.Not, not)
+			}
+		default:
+			if !query.addQualifier(term.text) {
+				query.Words = append(query.Words, term.text)
+				query.Text = gcse.AppendTokens(query.Text, []byte(term.text))
+			}
+		}
+	}
+	return query
+}
+
+// addQualifier returns false if word is not a valid "field:value" pair.
+func (q *Query) addQualifier(word string) bool {
+	p := strings.Index(word, ":")
+	if p <= 0 || p == len(word)-1 {
+		return false
+	}
+	field, value := strings.ToLower(word[:p]), word[p+1:]
+	switch field {
+	case "name":
+		q.Name = gcse.AppendTokens(q.Name, []byte(value))
+	case "sym":
+		q.Syms = append(q.Syms, value)
+	case "pkg":
+		q.Pkgs = append(q.Pkgs, value)
+	case "author":
+		q.Authors = append(q.Authors, value)
+	case "site":
+		q.Sites = append(q.Sites, strings.ToLower(value))
+	case "imports":
+		q.Imports = append(q.Imports, value)
+	case "kind":
+		switch kind := strings.ToLower(value); kind {
+		case "command", "library":
+			q.Kinds = append(q.Kinds, kind)
+		default:
+			return false
+		}
+	case "stars":
+		f, ok := parseIntFilter(value)
+		if !ok {
+			return false
+		}
+		q.Stars = append(q.Stars, f)
+	default:
+		return false
+	}
+	return true
+}
+
+// Tokens returns all tokens used for matching and highlighting.
+func (q *Query) Tokens() stringsp.Set {
+	var tokens stringsp.Set
+	tokens.Add(q.Text.Elements()...)
+	tokens.Add(q.Name.Elements()...)
+	return tokens
+}
+
+// SearchFields returns the query passed to database.Search.
+func (q *Query) SearchFields() map[string]stringsp.Set {
+	fields := map[string]stringsp.Set{gcse.IndexTextField: q.Text}
+	if len(q.Name) > 0 {
+		fields[gcse.IndexNameField] = q.Name
+	}
+	if len(q.Syms) > 0 {
+		fields[gcse.IndexSymbolField] = stringsp.NewSet(q.Syms...)
+	}
+	return fields
+}
+
+// MatchedSymbols returns the exported identifiers of hit matching the "sym:"
+// identifiers. A method matches its name, e.g. "Router.ServeHTTP" matches
+// "ServeHTTP".
+func (q *Query) MatchedSymbols(hit *gcse.HitInfo) []string {
+	var syms []string
+	for _, sym := range hit.Exported {
+		for _, v := range q.Syms {
+			if sym == v || strings.HasSuffix(sym, "."+v) {
+				syms = append(syms, sym)
+				break
+			}
+		}
+	}
+	return syms
+}
+
+func anyOf(values []string, f func(v string) bool) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if f(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns true if hit satisfies all the post-filters.
+func (q *Query) Match(hit *gcse.HitInfo) bool {
+	if !anyOf(q.Pkgs, func(v string) bool {
+		return strings.HasPrefix(hit.Package, v)
+	}) {
+		return false
+	}
+	if !anyOf(q.Authors, func(v string) bool {
+		return strings.EqualFold(hit.Author, v) || strings.EqualFold(gcse.AuthorOfPackage(hit.Package), v)
+	}) {
+		return false
+	}
+	if !anyOf(q.Sites, func(v string) bool {
+		return strings.ToLower(gcse.HostOfPackage(hit.Package)) == v
+	}) {
+		return false
+	}
+	if !anyOf(q.Imports, func(v string) bool {
+		for _, imp := range hit.Imports {
+			if imp == v {
+				return true
+			}
+		}
+		return false
+	}) {
+		return false
+	}
+	if !anyOf(q.Kinds, func(v string) bool {
+		return (hit.Name == "main") == (v == "command")
+	}) {
+		return false
+	}
+	for _, f := range q.Stars {
+		if !f.match(hit.StarCount) {
+			return false
+		}
+	}
+	for _, not := range q.Not {
+		if not.Match(hit) {
+			return false
+		}
+	}
+	return true
+}
+
+// phraseWords splits text into a sequence of normalized words. Each CJK
+// character is a word.
+func phraseWords(text string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, gcse.NormWord(string(word)))
+			word = word[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case gcse.IsCJK(r):
+			flush()
+			words = append(words, gcse.NormWord(string(r)))
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+func containsPhrase(words, phrase []string) bool {
+	if len(phrase) == 0 {
+		return true
+	}
+outer:
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		for j, w := range phrase {
+			if words[i+j] != w {
+				continue outer
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// MatchPhrases checks the quoted phrases against the synopsis and important
+// sentences of hit, then against the description and README of the full
+// package, which is only loaded from db when necessary.
+func (q *Query) MatchPhrases(db database, hit *gcse.HitInfo) bool {
+	if len(q.Phrases) == 0 && len(q.NotPhrases) == 0 {
+		return true
+	}
+	short := phraseWords(hit.Name + "\n" + hit.Synopsis + "\n" + strings.Join(hit.ImportantSentences, "\n"))
+	var full []string
+	fullLoaded := false
+	contains := func(phrase []string) bool {
+		if containsPhrase(short, phrase) {
+			return true
+		}
+		if !fullLoaded {
+			fullLoaded = true
+			if doc, found := db.FindFullPackage(hit.Package); found {
+				full = phraseWords(doc.Description + "\n" + gcse.ReadmeToText(doc.ReadmeFn, doc.ReadmeData))
+			}
+		}
+		return containsPhrase(full, phrase)
+	}
+	for _, phrase := range q.Phrases {
+		if !contains(phrase) {
+			return false
+		}
+	}
+	for _, phrase := range q.NotPhrases {
+		if contains(phrase) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golangplus/strings"
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestParseQuery(t *testing.T) {
+	q := parseQuery("yaml name:cobra author:spf13 site:GitHub.com imports:net/http stars:>100 foo:bar")
+	assert.Equal(t, "q.Text", q.Text, stringsp.NewSet("yaml", "foo", "bar", "foo-bar"))
+	assert.Equal(t, "q.Name", q.Name, stringsp.NewSet("cobra"))
+	assert.Equal(t, "q.Authors", q.Authors, []string{"spf13"})
+	assert.Equal(t, "q.Sites", q.Sites, []string{"github.com"})
+	assert.Equal(t, "q.Imports", q.Imports, []string{"net/http"})
+	assert.Equal(t, "q.Stars", q.Stars, []intFilter{{op: ">", n: 100}})
+
+	q = parseQuery("stars:many")
+	assert.Equal(t, "q.Stars", q.Stars, []intFilter(nil))
+	assert.Equal(t, "q.Text", q.Text, gcse.AppendTokens(nil, []byte("stars:many")))
+}
+
+func TestQuery_Match(t *testing.T) {
+	hit := &gcse.HitInfo{
+		DocInfo: gcse.DocInfo{
+			Package:   "github.com/spf13/cobra",
+			StarCount: 120,
+			Imports:   []string{"net/http"},
+		},
+	}
+	for _, c := range []struct {
+		q     string
+		match bool
+	}{
+		{"cobra", true},
+		{"author:spf13", true},
+		{"author:SPF13", true},
+		{"author:golang", false},
+		{"author:golang author:spf13", true},
+		{"site:github.com", true},
+		{"site:gitlab.com", false},
+		{"pkg:github.com/spf13", true},
+		{"pkg:github.com/golang", false},
+		{"imports:net/http", true},
+		{"imports:net", false},
+		{"stars:>100", true},
+		{"stars:>=120", true},
+		{"stars:<100", false},
+		{"stars:>100 stars:<200", true},
+		{"stars:>100 author:golang", false},
+		{"kind:library", true},
+		{"kind:command", false},
+		{"kind:command kind:library", true},
+	} {
+		assert.Equal(t, c.q, parseQuery(c.q).Match(hit), c.match)
+	}
+}
+
+func TestSplitQuery(t *testing.T) {
+	assert.Equal(t, "terms", splitQuery(`http router -gin "middleware chain" -"web framework" -  "unclosed`), []queryTerm{
+		{text: "http"},
+		{text: "router"},
+		{text: "gin", negated: true},
+		{text: "middleware chain", quoted: true},
+		{text: "web framework", negated: true, quoted: true},
+		{text: "unclosed", quoted: true},
+	})
+}
+
+func TestParseQuery_Negation(t *testing.T) {
+	q := parseQuery(`http -gin -name:mux -author:spf13 "middleware chain"`)
+	assert.Equal(t, "q.Text", q.Text, gcse.AppendTokens(stringsp.NewSet("http"), []byte("middleware chain")))
+	assert.Equal(t, "q.Excluded", q.Excluded, []map[string]stringsp.Set{
+		{gcse.IndexTextField: stringsp.NewSet("gin")},
+		{gcse.IndexNameField: stringsp.NewSet("mux")},
+	})
+	assert.Equal(t, "len(q.Not)", len(q.Not), 1)
+	assert.Equal(t, "q.Not[0].Authors", q.Not[0].Authors, []string{"spf13"})
+	assert.Equal(t, "q.Phrases", q.Phrases, [][]string{phraseWords("middleware chain")})
+
+	hit := &gcse.HitInfo{DocInfo: gcse.DocInfo{Package: "github.com/spf13/cobra"}}
+	assert.False(t, "match", q.Match(hit))
+}
+
+func TestParseQuery_Sym(t *testing.T) {
+	q := parseQuery("http sym:Router.ServeHTTP -sym:Handle")
+	assert.Equal(t, "q.Syms", q.Syms, []string{"Router.ServeHTTP"})
+	assert.Equal(t, "q.SearchFields()", q.SearchFields(), map[string]stringsp.Set{
+		gcse.IndexTextField:   stringsp.NewSet("http"),
+		gcse.IndexSymbolField: stringsp.NewSet("Router.ServeHTTP"),
+	})
+	assert.Equal(t, "q.Excluded", q.Excluded, []map[string]stringsp.Set{
+		{gcse.IndexSymbolField: stringsp.NewSet("Handle")},
+	})
+
+	hit := &gcse.HitInfo{DocInfo: gcse.DocInfo{
+		Exported: []string{"Handle", "NewRouter", "Router", "Router.ServeHTTP", "ServeHTTP"},
+	}}
+	assert.Equal(t, "MatchedSymbols", parseQuery("sym:ServeHTTP").MatchedSymbols(hit), []string{"Router.ServeHTTP", "ServeHTTP"})
+	assert.Equal(t, "MatchedSymbols", parseQuery("sym:Router").MatchedSymbols(hit), []string{"Router"})
+	assert.Equal(t, "MatchedSymbols", parseQuery("sym:router").MatchedSymbols(hit), []string(nil))
+}
+
+func TestContainsPhrase(t *testing.T) {
+	words := phraseWords("A simple HTTP router, with a middleware chain.")
+	assert.True(t, "middleware chain", containsPhrase(words, phraseWords("middleware chain")))
+	assert.True(t, "http router", containsPhrase(words, phraseWords("http router")))
+	assert.False(t, "router chain", containsPhrase(words, phraseWords("router chain")))
+	assert.False(t, "chain middleware", containsPhrase(words, phraseWords("chain middleware")))
+}
+
+func TestContainsPhrase_CJK(t *testing.T) {
+	words := phraseWords("一个Go语言的中文输入法")
+	assert.True(t, "中文输入", containsPhrase(words, phraseWords("中文输入")))
+	assert.True(t, "go语言", containsPhrase(words, phraseWords("go语言")))
+	assert.False(t, "中文法", containsPhrase(words, phraseWords("中文法")))
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/gcse/configs"
+)
+
+const (
+	defaultScorer = "default"
+	bm25Scorer    = "bm25"
+)
+
+// scorerName returns name if it is a known scorer, or the configured one
+// otherwise.
+func scorerName(name string) string {
+	for _, n := range []string{name, configs.SearchScorer} {
+		switch n = strings.ToLower(n); n {
+		case defaultScorer, bm25Scorer:
+			return n
+		}
+	}
+	return defaultScorer
+}
+
+// matchScorer calculates how well hits match the tokens of a query, by
+// gcse.CalcMatchScore or gcse.CalcBM25FScore.
+//
+// The idfs of synonyms added by the expansion are lowered by synonymWeight.
+// Since a hit may not contain all the tokens if there are synonyms, the idfs
+// of the tokens not in the hit are zeroed before scoring it.
+type matchScorer struct {
+	name      string
+	tokenList []string
+	exp       *expansion
+
+	// idfs of the default scorer
+	textIdfs, nameIdfs []float64
+	// idfs and statistics of the BM25F scorer
+	bm25Idfs  []float64
+	bm25Stats *gcse.BM25Stats
+	// sorted docIDs of each token at least each of gcse.FreqBuckets times in
+	// the text field
+	freqDocs [][][]int32
+
+	// the idfs and the text frequencies of the current hit
+	hitTextIdfs, hitNameIdfs, hitBM25Idfs []float64
+	hitFreqs                              []int
+}
+
+func newMatchScorer(db database, name string, tokenList []string, exp *expansion) *matchScorer {
+	s := &matchScorer{
+		name:      scorerName(name),
+		tokenList: tokenList,
+		exp:       exp,
+	}
+	switch s.name {
+	case bm25Scorer:
+		N := db.PackageCount()
+		s.bm25Idfs = make([]float64, len(tokenList))
+		for i, token := range tokenList {
+			s.bm25Idfs[i] = gcse.BM25Idf(db.PackageCountOfToken(gcse.IndexTextField, token), N)
+			if exp.IsAdded(token) {
+				s.bm25Idfs[i] *= synonymWeight
+			}
+		}
+		s.bm25Stats = db.BM25Stats()
+		s.freqDocs = make([][][]int32, len(tokenList))
+		for i, token := range tokenList {
+			for _, bucket := range gcse.FreqBuckets {
+				s.freqDocs[i] = append(s.freqDocs[i], db.TokenDocs(gcse.IndexTextFreqField, gcse.FreqToken(token, bucket)))
+			}
+		}
+		s.hitFreqs = make([]int, len(tokenList))
+		s.hitBM25Idfs = s.bm25Idfs
+		if exp != nil {
+			s.hitBM25Idfs = make([]float64, len(tokenList))
+		}
+	default:
+		s.textIdfs, s.nameIdfs = tokenIdfs(db, tokenList)
+		for i, token := range tokenList {
+			if exp.IsAdded(token) {
+				s.textIdfs[i] *= synonymWeight
+				s.nameIdfs[i] *= synonymWeight
+			}
+		}
+		s.hitTextIdfs, s.hitNameIdfs = s.textIdfs, s.nameIdfs
+		if exp != nil {
+			s.hitTextIdfs = make([]float64, len(tokenList))
+			s.hitNameIdfs = make([]float64, len(tokenList))
+		}
+	}
+	return s
+}
+
+// setHit sets the idfs and the text frequencies of the hit of docID. Not safe
+// for concurrent use.
+func (s *matchScorer) setHit(docID int32) {
+	for i, docs := range s.freqDocs {
+		s.hitFreqs[i] = 1
+		for k, bucket := range gcse.FreqBuckets {
+			if containsDoc(docs[k], docID) {
+				s.hitFreqs[i] = bucket
+				break
+			}
+		}
+	}
+	if s.exp == nil {
+		return
+	}
+	for i, token := range s.tokenList {
+		w := 1.
+		if !s.exp.Contains(docID, token) {
+			w = 0
+		}
+		if s.name == bm25Scorer {
+			s.hitBM25Idfs[i] = w * s.bm25Idfs[i]
+		} else {
+			s.hitTextIdfs[i] = w * s.textIdfs[i]
+			s.hitNameIdfs[i] = w * s.nameIdfs[i]
+		}
+	}
+}
+
+func (s *matchScorer) Score(docID int32, hit *gcse.HitInfo) float64 {
+	s.setHit(docID)
+	if s.name == bm25Scorer {
+		return gcse.CalcBM25FScore(hit, s.tokenList, s.hitFreqs, s.hitBM25Idfs, s.bm25Stats)
+	}
+	return gcse.CalcMatchScore(hit, s.tokenList, s.hitTextIdfs, s.hitNameIdfs)
+}
+
+// Explain returns the score, the part of it independent of tokens and the
+// contributions of tokens.
+func (s *matchScorer) Explain(docID int32, hit *gcse.HitInfo) (score, base float64, matches []gcse.TokenMatch) {
+	s.setHit(docID)
+	if s.name == bm25Scorer {
+		score, matches = gcse.ExplainBM25FScore(hit, s.tokenList, s.hitFreqs, s.hitBM25Idfs, s.bm25Stats)
+		if len(s.tokenList) == 0 {
+			base = score
+		}
+		return score, base, matches
+	}
+	score, matches = gcse.ExplainMatchScore(hit, s.tokenList, s.hitTextIdfs, s.hitNameIdfs)
+	return score, gcse.BaseMatchScore(len(s.tokenList)), matches
+}
+
+// exactMatchBonus is the maximum increase of the match score of a package
+// containing all the query words unstemmed.
+const exactMatchBonus = 0.2
+
+// exactMatcher ranks packages containing the unstemmed query words higher
+// than those only matching their stems.
+type exactMatcher struct {
+	// sorted docIDs of each raw token
+	docs [][]int32
+}
+
+// newExactMatcher returns nil if there are no words.
+func newExactMatcher(db database, words []string) *exactMatcher {
+	raw := gcse.AppendRawTokens(nil, []byte(strings.Join(words, " ")))
+	if len(raw) == 0 {
+		return nil
+	}
+	m := &exactMatcher{}
+	for token := range raw {
+		m.docs = append(m.docs, db.TokenDocs(gcse.IndexRawField, token))
+	}
+	return m
+}
+
+// Bonus returns the factor of the match score of the package of docID.
+func (m *exactMatcher) Bonus(docID int32) float64 {
+	if m == nil {
+		return 1
+	}
+	matched := 0
+	for _, docs := range m.docs {
+		if containsDoc(docs, docID) {
+			matched++
+		}
+	}
+	return 1 + exactMatchBonus*float64(matched)/float64(len(m.docs))
+}
+
+// containsDoc returns whether the sorted docs contain docID.
+func containsDoc(docs []int32, docID int32) bool {
+	i := sort.Search(len(docs), func(i int) bool {
+		return docs[i] >= docID
+	})
+	return i < len(docs) && docs[i] == docID
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+)
+
+func TestExactMatcher_Bonus(t *testing.T) {
+	db := tokenDocsDB{docs: map[string][]int32{
+		"parsers": {1, 3},
+		"json":    {1, 2, 5},
+	}}
+	m := newExactMatcher(db, []string{"Parsers", "json"})
+	assert.Equal(t, "both", m.Bonus(1), 1+exactMatchBonus)
+	assert.Equal(t, "one", m.Bonus(2), 1+exactMatchBonus/2)
+	assert.Equal(t, "none", m.Bonus(4), 1.)
+
+	assert.Equal(t, "no words", newExactMatcher(db, nil).Bonus(1), 1.)
+}
+
+func TestMatchScorer_hitFreqs(t *testing.T) {
+	// The docIDs of "yaml" at least 64, 32, 16, 8, 4 and 2 times.
+	s := &matchScorer{
+		freqDocs: [][][]int32{{nil, nil, nil, {3}, {1}, {2}}},
+		hitFreqs: make([]int, 1),
+	}
+	for docID, freq := range []int{1, 4, 2, 8} {
+		s.setHit(int32(docID))
+		assert.Equal(t, "hitFreqs", s.hitFreqs, []int{freq})
+	}
+}
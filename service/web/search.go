@@ -1,6 +1,7 @@
 package main
 
 import (
+	"container/heap"
 	"html/template"
 	"log"
 	"math"
@@ -23,11 +24,50 @@ type Hit struct {
 	gcse.HitInfo
 	MatchScore float64
 	Score      float64
+	// Forks are the hits of the same upstream package ranked after this one,
+	// i.e. forks of its project or of the project it is forked from.
+	Forks []*Hit
+	// Symbols are the exported identifiers matching the "sym:" qualifiers.
+	Symbols []string
+	// Explain is set only if an explanation is required.
+	Explain *Explanation
+
+	docID int32
+}
+
+// Explanation is the score breakdown of a hit. Score is
+// max(StaticScore, TestStaticScore) * MatchScore * ExactBonus. MatchScore is
+// BaseMatchScore plus the scores of the tokens. ExactBonus is for containing
+// the unstemmed query words.
+type Explanation struct {
+	StaticScore     float64           `json:"staticscore"`
+	TestStaticScore float64           `json:"teststaticscore"`
+	MatchScore      float64           `json:"matchscore"`
+	ExactBonus      float64           `json:"exactbonus"`
+	BaseMatchScore  float64           `json:"basematchscore"`
+	Tokens          []gcse.TokenMatch `json:"tokens"`
+	Score           float64           `json:"score"`
+	Scorer          string            `json:"scorer"`
 }
 
 type SearchResult struct {
+	// TotalResults is the number of all hits, which could be more than
+	// len(Hits).
 	TotalResults int
+	// TotalEntries is the number of all hits after collapsing forks, and
+	// folding sub-packages if searchOptions.FoldSubPackages.
+	TotalEntries int
 	Hits         []*Hit
+	// Corrected is the spelling corrected query whose results are returned
+	// because the original query matched nothing.
+	Corrected string
+	// Facets are counted over all hits.
+	Facets *Facets
+	// Expansions maps query tokens to their synonyms also searched.
+	Expansions map[string][]string
+
+	scorer *matchScorer
+	exact  *exactMatcher
 }
 
 var stopWords = stringsp.NewSet(
@@ -45,88 +85,303 @@ func idf(df, N int) float64 {
 	return idf
 }
 
-func search(tr trace.Trace, db database, q string) (*SearchResult, stringsp.Set, error) {
-	tokens := gcse.AppendTokens(nil, []byte(q))
-	tokenList := tokens.Elements()
-	log.Printf("tokens for query %s: %v", q, tokens)
+// rankedBefore returns true if a hit of score, stars and pkg is ranked before
+// another.
+func rankedBefore(scoreA, scoreB float64, starsA, starsB int, pkgA, pkgB string) bool {
+	if scoreA != scoreB {
+		return scoreA > scoreB
+	}
+	if starsA != starsB {
+		return starsA > starsB
+	}
+	if len(pkgA) != len(pkgB) {
+		return len(pkgA) < len(pkgB)
+	}
+	return pkgA < pkgB
+}
 
-	var hits []*Hit
+// hitBefore returns true if hit a is ranked before hit b.
+func hitBefore(a, b *Hit) bool {
+	return rankedBefore(a.Score, b.Score, a.StarCount, b.StarCount, a.Package, b.Package)
+}
 
-	N := db.PackageCount()
-	textIdfs := make([]float64, len(tokenList))
-	nameIdfs := make([]float64, len(tokenList))
-	for i := range textIdfs {
-		textIdfs[i] = idf(db.PackageCountOfToken(gcse.IndexTextField, tokenList[i]), N)
-		nameIdfs[i] = idf(db.PackageCountOfToken(gcse.IndexNameField, tokenList[i]), N)
+// hitCandidate is a matched hit kept compactly until it is in the top hits.
+type hitCandidate struct {
+	docID      int32
+	matchScore float64
+	score      float64
+	stars      int
+	pkg        string
+	upstream   string
+}
+
+func newHitCandidate(hit *Hit) hitCandidate {
+	return hitCandidate{
+		docID:      hit.docID,
+		matchScore: hit.MatchScore,
+		score:      hit.Score,
+		stars:      hit.StarCount,
+		pkg:        hit.Package,
+		upstream:   gcse.UpstreamPackage(hit.Package, hit.ForkOf),
 	}
+}
 
-	db.Search(map[string]stringsp.Set{gcse.IndexTextField: tokens},
-		func(docID int32, data interface{}) error {
-			hit := &Hit{}
-			var ok bool
-			hit.HitInfo, ok = data.(gcse.HitInfo)
-			if !ok {
-				log.Print("ok = false")
-			}
+func (c *hitCandidate) before(o *hitCandidate) bool {
+	return rankedBefore(c.score, o.score, c.stars, o.stars, c.pkg, o.pkg)
+}
 
-			hit.MatchScore = gcse.CalcMatchScore(&hit.HitInfo, tokenList, textIdfs, nameIdfs)
-			hit.Score = math.Max(hit.StaticScore, hit.TestStaticScore) * hit.MatchScore
+// candidateHeap is a heap of the indexes of candidates, the best on top.
+type candidateHeap struct {
+	cands []hitCandidate
+	idxs  []int
+}
 
-			hits = append(hits, hit)
-			return nil
+func (h *candidateHeap) Len() int           { return len(h.idxs) }
+func (h *candidateHeap) Less(i, j int) bool { return h.cands[h.idxs[i]].before(&h.cands[h.idxs[j]]) }
+func (h *candidateHeap) Swap(i, j int)      { h.idxs[i], h.idxs[j] = h.idxs[j], h.idxs[i] }
+func (h *candidateHeap) Push(x interface{}) { h.idxs = append(h.idxs, x.(int)) }
+func (h *candidateHeap) Pop() interface{} {
+	i := h.idxs[len(h.idxs)-1]
+	h.idxs = h.idxs[:len(h.idxs)-1]
+	return i
+}
+
+// foldParent returns the closest ancestor package of pkg for which isEntry
+// returns true, into which pkg is folded, and the sub path of pkg under it.
+func foldParent(pkg string, isEntry func(pkg string) bool) (parent, subPath string, ok bool) {
+	parts := strings.Split(pkg, "/")
+	for i := len(parts) - 1; i >= 2; i-- {
+		if parent := strings.Join(parts[:i], "/"); isEntry(parent) {
+			return parent, "/" + strings.Join(parts[i:], "/"), true
+		}
+	}
+	return "", "", false
+}
+
+// countEntries returns the number of the heads of cands which are not folded
+// into the head of an ancestor package ranked before them, i.e. the entries
+// shown if all heads are shown in rank order.
+func countEntries(cands []hitCandidate, heads []int) int {
+	byPkg := make(map[string]int, len(heads))
+	for _, i := range heads {
+		byPkg[cands[i].pkg] = i
+	}
+	memo := make(map[int]bool)
+	var isEntry func(i int) bool
+	isEntry = func(i int) bool {
+		if entry, ok := memo[i]; ok {
+			return entry
+		}
+		c := &cands[i]
+		_, _, folded := foldParent(c.pkg, func(pkg string) bool {
+			j, ok := byPkg[pkg]
+			return ok && cands[j].before(c) && isEntry(j)
 		})
-	tr.LazyPrintf("Got %d hits for query %q", len(hits), q)
+		memo[i] = !folded
+		return !folded
+	}
+	cnt := 0
+	for _, i := range heads {
+		if isEntry(i) {
+			cnt++
+		}
+	}
+	return cnt
+}
 
-	swapHits := func(i, j int) {
-		hits[i], hits[j] = hits[j], hits[i]
+// topHits returns the best hits, in rank order, of those generated by forEach,
+// the total number of hits and the number of entries. The hits of the same
+// upstream package are collapsed into the Forks of the best one of them, the
+// head. Entries are the heads, or, if fold is true, the heads not folded into
+// the head of an ancestor package ranked before them. Hits are taken until
+// limit entries are taken.
+//
+// forEach is called once, and the hits are kept compactly and materialized by
+// hitOf only if returned.
+func topHits(forEach func(out func(*Hit)), hitOf func(*hitCandidate) *Hit, limit int, fold bool) (hits []*Hit, total, entries int) {
+	if limit < 1 {
+		limit = 1
 	}
-	sortp.SortF(len(hits), func(i, j int) bool {
-		// true if doc i is before doc j
-		ssi, ssj := hits[i].Score, hits[j].Score
-		if ssi > ssj {
-			return true
+	var cands []hitCandidate
+	forEach(func(hit *Hit) {
+		cands = append(cands, newHitCandidate(hit))
+	})
+	heads := make(map[string]int)
+	for i := range cands {
+		if j, ok := heads[cands[i].upstream]; !ok || cands[i].before(&cands[j]) {
+			heads[cands[i].upstream] = i
 		}
-		if ssi < ssj {
-			return false
+	}
+	h := &candidateHeap{cands: cands}
+	forks := make(map[string][]int)
+	for i := range cands {
+		if up := cands[i].upstream; heads[up] == i {
+			h.idxs = append(h.idxs, i)
+		} else {
+			forks[up] = append(forks[up], i)
 		}
-		sci, scj := hits[i].StarCount, hits[j].StarCount
-		if sci > scj {
-			return true
+	}
+	entries = len(h.idxs)
+	if fold {
+		entries = countEntries(cands, h.idxs)
+	}
+	heap.Init(h)
+	taken := make(map[string]bool)
+	for h.Len() > 0 {
+		c := &cands[heap.Pop(h).(int)]
+		if !fold || !isFolded(c.pkg, taken) {
+			if len(taken) == limit {
+				break
+			}
+			taken[c.pkg] = true
 		}
-		if sci < scj {
-			return false
+		hit := hitOf(c)
+		fs := forks[c.upstream]
+		sortp.SortF(len(fs), func(i, j int) bool {
+			return cands[fs[i]].before(&cands[fs[j]])
+		}, func(i, j int) {
+			fs[i], fs[j] = fs[j], fs[i]
+		})
+		for _, i := range fs {
+			hit.Forks = append(hit.Forks, hitOf(&cands[i]))
 		}
-		pi, pj := hits[i].Package, hits[j].Package
-		if len(pi) < len(pj) {
-			return true
+		hits = append(hits, hit)
+	}
+	return hits, len(cands), entries
+}
+
+func isFolded(pkg string, entries map[string]bool) bool {
+	_, _, ok := foldParent(pkg, func(pkg string) bool {
+		return entries[pkg]
+	})
+	return ok
+}
+
+// tokenIdfs returns the idfs of tokens in the text and name fields.
+func tokenIdfs(db database, tokenList []string) (textIdfs, nameIdfs []float64) {
+	N := db.PackageCount()
+	textIdfs = make([]float64, len(tokenList))
+	nameIdfs = make([]float64, len(tokenList))
+	for i := range textIdfs {
+		textIdfs[i] = idf(db.PackageCountOfToken(gcse.IndexTextField, tokenList[i]), N)
+		nameIdfs[i] = idf(db.PackageCountOfToken(gcse.IndexNameField, tokenList[i]), N)
+	}
+	return textIdfs, nameIdfs
+}
+
+// explainHits sets the Explain field of the hits of results.
+func explainHits(results *SearchResult) {
+	ms := results.scorer
+	for _, hit := range results.Hits {
+		matchScore, base, matches := ms.Explain(hit.docID, &hit.HitInfo)
+		hit.Explain = &Explanation{
+			StaticScore:     hit.StaticScore,
+			TestStaticScore: hit.TestStaticScore,
+			MatchScore:      matchScore,
+			ExactBonus:      results.exact.Bonus(hit.docID),
+			BaseMatchScore:  base,
+			Tokens:          matches,
+			Score:           hit.Score,
+			Scorer:          ms.name,
 		}
-		if len(pi) > len(pj) {
-			return false
+	}
+}
+
+// searchOptions are the options of search other than the query.
+type searchOptions struct {
+	// Limit is the maximum number of hits, with forks collapsed, returned,
+	// or all of them if <= 0.
+	Limit int
+	// FoldSubPackages makes Limit count the hits not folded into the hit of
+	// an ancestor package ranked before them, as showSearchResults does.
+	// The hits folded are returned in addition.
+	FoldSubPackages bool
+	// Scorer is the name of the match scorer. The configured one is used if
+	// empty or unknown.
+	Scorer string
+}
+
+// search returns the top hits of q. The TotalResults is the number of all
+// hits.
+func search(tr trace.Trace, db database, q string, opts searchOptions) (*SearchResult, stringsp.Set, error) {
+	query := parseQuery(q)
+	exp := newExpansion(db, query, querySynonyms)
+	tokens := query.Tokens()
+	tokens.Add(exp.Added().Elements()...)
+	tokenList := tokens.Elements()
+	log.Printf("tokens for query %s: %v", q, tokens)
+
+	scorer := newMatchScorer(db, opts.Scorer, tokenList, exp)
+	exact := newExactMatcher(db, query.Words)
+
+	excluded := make(map[int32]bool)
+	for _, q := range query.Excluded {
+		db.Search(q, func(docID int32, _ interface{}) error {
+			excluded[docID] = true
+			return nil
+		})
+	}
+
+	facets := newFacetCounter()
+	forEachHit := func(out func(*Hit)) {
+		// A package could be found by more than one synonym.
+		found := make(map[int32]bool)
+		for _, fields := range exp.SearchFields(query) {
+			db.Search(fields, func(docID int32, data interface{}) error {
+				if excluded[docID] || found[docID] || !exp.Match(docID) {
+					return nil
+				}
+				found[docID] = true
+				hit := &Hit{docID: docID}
+				var ok bool
+				hit.HitInfo, ok = data.(gcse.HitInfo)
+				if !ok {
+					log.Print("ok = false")
+				}
+				if !query.Match(&hit.HitInfo) || !query.MatchPhrases(db, &hit.HitInfo) {
+					return nil
+				}
+
+				hit.MatchScore = scorer.Score(docID, &hit.HitInfo) * exact.Bonus(docID)
+				hit.Score = math.Max(hit.StaticScore, hit.TestStaticScore) * hit.MatchScore
+
+				facets.add(&hit.HitInfo)
+				out(hit)
+				return nil
+			})
 		}
-		return pi < pj
-	}, swapHits)
-
-	tr.LazyPrintf("Results sorted")
-
-	if len(hits) < 5000 {
-		// Adjust Score by down ranking duplicated packages
-		pkgCount := make(map[string]int)
-		for _, hit := range hits {
-			cnt := pkgCount[hit.Name] + 1
-			pkgCount[hit.Name] = cnt
-			if cnt > 1 && hit.ImportedLen == 0 && hit.TestImportedLen == 0 {
-				hit.Score /= float64(cnt)
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = db.PackageCount()
+	}
+	hitOf := func(c *hitCandidate) *Hit {
+		hit := &Hit{docID: c.docID, MatchScore: c.matchScore, Score: c.score}
+		hit.HitInfo, _ = db.PackageOfDoc(c.docID)
+		hit.Symbols = query.MatchedSymbols(&hit.HitInfo)
+		return hit
+	}
+	hits, total, entries := topHits(forEachHit, hitOf, limit, opts.FoldSubPackages)
+	tr.LazyPrintf("Got top %d of %d hits for query %q", len(hits), total, q)
+	if total == 0 {
+		if corrected := correctQuery(db, q); corrected != "" {
+			tr.LazyPrintf("Query corrected to %q", corrected)
+			results, tokens, err := search(tr, db, corrected, opts)
+			if err != nil {
+				return nil, nil, err
 			}
+			results.Corrected = corrected
+			return results, tokens, nil
 		}
-		// Re-sort
-		sortp.BubbleF(len(hits), func(i, j int) bool {
-			return hits[i].Score > hits[j].Score
-		}, swapHits)
-		tr.LazyPrintf("Results reranked")
 	}
 	return &SearchResult{
-		TotalResults: len(hits),
+		TotalResults: total,
+		TotalEntries: entries,
 		Hits:         hits,
+		Facets:       facets.facets(q),
+		Expansions:   exp.Synonyms(),
+		scorer:       scorer,
+		exact:        exact,
 	}, tokens, nil
 }
 
@@ -236,6 +491,9 @@ type ShowDocInfo struct {
 
 type ShowResults struct {
 	TotalResults int
+	Corrected    string
+	Facets       *Facets
+	Expansions   map[string][]string
 	TotalEntries int
 	Folded       int
 	Docs         []ShowDocInfo
@@ -295,31 +553,26 @@ func showSearchResults(db database, results *SearchResult, tokens stringsp.Set,
 	folded := 0
 
 	cnt := 0
-mainLoop:
 	for _, d := range results.Hits {
 		d.Name = packageShowName(d.Name, d.Package)
 
-		parts := strings.Split(d.Package, "/")
-		if len(parts) > 2 {
-			// try fold it (if its parent has been in the list)
-			for i := len(parts) - 1; i >= 2; i-- {
-				pkg := strings.Join(parts[:i], "/")
-				if idx, ok := projToIdx[pkg]; ok {
-					markedName := markText(d.Name, tokens, markWord)
-					if r.In(idx) {
-						docsIdx := idx - r.start
-						docs[docsIdx].Subs = append(docs[docsIdx].Subs,
-							SubProjectInfo{
-								MarkedName: markedName,
-								Package:    d.Package,
-								SubPath:    "/" + strings.Join(parts[i:], "/"),
-								Info:       d.Synopsis,
-							})
-					}
-					folded++
-					continue mainLoop
-				}
+		// try fold it (if its parent has been in the list)
+		if parent, subPath, ok := foldParent(d.Package, func(pkg string) bool {
+			_, ok := projToIdx[pkg]
+			return ok
+		}); ok {
+			if idx := projToIdx[parent]; r.In(idx) {
+				docsIdx := idx - r.start
+				docs[docsIdx].Subs = append(docs[docsIdx].Subs,
+					SubProjectInfo{
+						MarkedName: markText(d.Name, tokens, markWord),
+						Package:    d.Package,
+						SubPath:    subPath,
+						Info:       d.Synopsis,
+					})
 			}
+			folded++
+			continue
 		}
 		projToIdx[d.Package] = cnt
 		if r.In(cnt) {
@@ -354,13 +607,20 @@ mainLoop:
 	}
 	return &ShowResults{
 		TotalResults: results.TotalResults,
-		TotalEntries: cnt,
+		Corrected:    results.Corrected,
+		Facets:       results.Facets,
+		Expansions:   results.Expansions,
+		TotalEntries: results.TotalEntries,
 		Folded:       folded,
 		Docs:         docs,
 	}
 }
 
-const itemsPerPage = 10
+const (
+	itemsPerPage = 10
+	// Pages after this are not served, which bounds the hits retrieved.
+	maxSearchPage = 100
+)
 
 func pageSearch(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html")
@@ -370,23 +630,38 @@ func pageSearch(w http.ResponseWriter, r *http.Request) {
 
 	// current page, 1-based
 	p, err := strconv.Atoi(r.FormValue("p"))
-	if err != nil {
+	if err != nil || p < 1 {
 		p = 1
 	}
+	if p > maxSearchPage {
+		p = maxSearchPage
+	}
 	startTime := time.Now()
 
 	q := strings.TrimSpace(r.FormValue("q"))
 	db := getDatabase()
-	results, tokens, err := search(tr, db, q)
+	scorer := r.FormValue("scorer")
+	results, tokens, err := search(tr, db, q, searchOptions{
+		Limit:           p * itemsPerPage,
+		FoldSubPackages: true,
+		Scorer:          scorer,
+	})
 	if err != nil {
 		tr.LazyPrintf("search failed: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 	tr.LazyPrintf("Search success with %d hits and %d tokens", len(results.Hits), len(tokens))
+	explain := r.FormValue("explain") == "1"
+	if explain {
+		explainHits(results)
+	}
 	showResults := showSearchResults(db, results, tokens, Range{(p - 1) * itemsPerPage, itemsPerPage})
 	tr.LazyPrintf("showSearchResults with %d results", len(showResults.Docs))
 	totalPages := (showResults.TotalEntries + itemsPerPage - 1) / itemsPerPage
+	if totalPages > maxSearchPage {
+		totalPages = maxSearchPage
+	}
 	log.Printf("totalPages: %d", totalPages)
 	var beforePages, afterPages []int
 	for i := 1; i <= totalPages; i++ {
@@ -427,6 +702,8 @@ func pageSearch(w http.ResponseWriter, r *http.Request) {
 		AfterPages  []int
 		BottomQ     bool
 		TotalPages  int
+		Explain     bool
+		Scorer      string
 	}{
 		Q:           q,
 		Results:     showResults,
@@ -438,6 +715,8 @@ func pageSearch(w http.ResponseWriter, r *http.Request) {
 		AfterPages:  afterPages,
 		BottomQ:     len(results.Hits) >= 5,
 		TotalPages:  totalPages,
+		Explain:     explain,
+		Scorer:      scorer,
 	}
 	log.Printf("Search results ready")
 	err = templates.ExecuteTemplate(w, "search.html", data)
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestTopHits(t *testing.T) {
+	var infos []gcse.HitInfo
+	for i := 0; i < 30; i++ {
+		info := gcse.HitInfo{}
+		info.Package = fmt.Sprintf("github.com/user%d/pkg", i)
+		// Every three packages are of the same upstream package, the second
+		// one, ranking after a fork of it.
+		if up := i - i%3 + 1; i != up {
+			info.ForkOf = fmt.Sprintf("github.com/user%d/pkg", up)
+		}
+		info.StaticScore = float64(100 - i)
+		infos = append(infos, info)
+	}
+	forEach, hitOf := hitsOfInfos(infos)
+	all, total, entries := topHits(forEach, hitOf, len(infos), false)
+	assert.Equal(t, "total", total, len(infos))
+	assert.Equal(t, "entries", entries, len(infos)/3)
+	assert.Equal(t, "len(all)", len(all), len(infos)/3)
+	for i, hit := range all {
+		assert.Equal(t, "Package", hit.Package, fmt.Sprintf("github.com/user%d/pkg", 3*i))
+		assert.Equal(t, "len(Forks)", len(hit.Forks), 2)
+		assert.Equal(t, "Forks[0]", hit.Forks[0].Package, fmt.Sprintf("github.com/user%d/pkg", 3*i+1))
+	}
+	for _, limit := range []int{1, 3, 5, 10} {
+		hits, total, _ := topHits(forEach, hitOf, limit, false)
+		assert.Equal(t, "total", total, len(infos))
+		assert.Equal(t, "len(hits)", len(hits), limit)
+		for i, hit := range hits {
+			assert.Equal(t, fmt.Sprintf("top %d", limit), hit.Package, all[i].Package)
+			assert.Equal(t, fmt.Sprintf("top %d forks", limit), len(hit.Forks), 2)
+		}
+	}
+	hits, total, _ := topHits(func(func(*Hit)) {}, hitOf, 10, false)
+	assert.Equal(t, "total", total, 0)
+	assert.Equal(t, "len(hits)", len(hits), 0)
+}
+
+// hitsOfInfos returns the forEach and hitOf of topHits for infos, scored by
+// their static scores.
+func hitsOfInfos(infos []gcse.HitInfo) (func(out func(*Hit)), func(*hitCandidate) *Hit) {
+	return func(out func(*Hit)) {
+			for i, info := range infos {
+				out(&Hit{HitInfo: info, Score: info.StaticScore, docID: int32(i)})
+			}
+		}, func(c *hitCandidate) *Hit {
+			return &Hit{HitInfo: infos[c.docID], Score: c.score, docID: c.docID}
+		}
+}
+
+func TestTopHits_fold(t *testing.T) {
+	var infos []gcse.HitInfo
+	for i, pkg := range []string{
+		"github.com/a/p",
+		"github.com/a/p/sub",
+		"github.com/b/q/sub",
+		"github.com/b/q",
+		"github.com/c/r",
+	} {
+		info := gcse.HitInfo{}
+		info.Package = pkg
+		info.StaticScore = float64(100 - i)
+		infos = append(infos, info)
+	}
+	forEach, hitOf := hitsOfInfos(infos)
+	pkgs := func(hits []*Hit) []string {
+		var res []string
+		for _, hit := range hits {
+			res = append(res, hit.Package)
+		}
+		return res
+	}
+
+	// a/p/sub is folded, b/q/sub ranks before b/q.
+	hits, total, entries := topHits(forEach, hitOf, 1, true)
+	assert.Equal(t, "total", total, 5)
+	assert.Equal(t, "entries", entries, 4)
+	assert.Equal(t, "hits", pkgs(hits), []string{"github.com/a/p", "github.com/a/p/sub"})
+
+	hits, _, _ = topHits(forEach, hitOf, 3, true)
+	assert.Equal(t, "hits", pkgs(hits), []string{"github.com/a/p", "github.com/a/p/sub", "github.com/b/q/sub", "github.com/b/q"})
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/golangplus/sort"
+	"github.com/golangplus/strings"
+
+	"github.com/daviddengcn/gcse"
+)
+
+const (
+	defaultSimilarCount = 10
+	maxSimilarCount     = 50
+	// A token, import or importer shared by more packages is too common to
+	// tell similarity, and too slow to go through.
+	maxSimilarFeatureDocs = 1000
+	// The cache is reset when it has more packages.
+	maxSimilarCacheSize = 10000
+)
+
+// SimilarPackage is a package similar to another one.
+type SimilarPackage struct {
+	Package  string  `json:"package"`
+	Name     string  `json:"name"`
+	Synopsis string  `json:"synopsis"`
+	Score    float64 `json:"score"`
+}
+
+// findSimilarPackages returns at most n packages most similar to hit. The
+// similarity to a package is the sum of the idfs of what they share:
+//   - tokens of the names and synopses,
+//   - imports, weighted by the number of their importers,
+//   - importers, weighted by the number of their imports.
+//
+// Only the in-memory hits and postings are read.
+func findSimilarPackages(db database, hit *gcse.HitInfo, n int) []SimilarPackage {
+	N := db.PackageCount()
+	scores := make(map[int32]float64)
+	addDocs := func(docs []int32, w float64) {
+		for _, docID := range docs {
+			scores[docID] += w
+		}
+	}
+
+	var tokens stringsp.Set
+	tokens = gcse.AppendTokens(tokens, []byte(hit.Name))
+	tokens = gcse.AppendTokens(tokens, []byte(hit.Synopsis))
+	for token := range tokens {
+		if stopWords.Contain(token) {
+			continue
+		}
+		docs := db.TokenDocs(gcse.IndexTextField, token)
+		if len(docs) > maxSimilarFeatureDocs {
+			continue
+		}
+		addDocs(docs, idf(len(docs), N))
+	}
+	for _, imp := range hit.Imports {
+		importers := db.TokenDocs(gcse.IndexImportsField, imp)
+		if len(importers) > maxSimilarFeatureDocs {
+			continue
+		}
+		addDocs(importers, idf(len(importers), N))
+	}
+	importers := db.TokenDocs(gcse.IndexImportsField, hit.Package)
+	if len(importers) > maxSimilarFeatureDocs {
+		importers = importers[:maxSimilarFeatureDocs]
+	}
+	for _, docID := range importers {
+		importer, found := db.PackageOfDoc(docID)
+		if !found {
+			continue
+		}
+		w := idf(len(importer.Imports), N)
+		for _, imp := range importer.Imports {
+			// Imports not in the index are skipped.
+			for _, docID := range db.TokenDocs(gcse.IndexPkgField, imp) {
+				scores[docID] += w
+			}
+		}
+	}
+
+	res := make([]SimilarPackage, 0, len(scores))
+	for docID, score := range scores {
+		h, found := db.PackageOfDoc(docID)
+		if !found || h.Package == hit.Package {
+			continue
+		}
+		res = append(res, SimilarPackage{
+			Package:  h.Package,
+			Name:     h.Name,
+			Synopsis: strings.TrimSpace(h.Synopsis),
+			Score:    score,
+		})
+	}
+	sortp.SortF(len(res), func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Package < res[j].Package
+	}, func(i, j int) {
+		res[i], res[j] = res[j], res[i]
+	})
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// similarCache keeps the similar packages of the packages of an index
+// segment.
+type similarCache struct {
+	sync.Mutex
+	packages map[string][]SimilarPackage
+}
+
+// get returns the maxSimilarCount similar packages of id, finding them by
+// calling find if not cached.
+func (c *similarCache) get(id string, find func() []SimilarPackage) []SimilarPackage {
+	c.Lock()
+	similar, ok := c.packages[id]
+	c.Unlock()
+	if ok {
+		return similar
+	}
+	// Found without the lock, a package could be found more than once
+	// concurrently, which is harmless.
+	similar = find()
+
+	c.Lock()
+	defer c.Unlock()
+	if c.packages == nil || len(c.packages) >= maxSimilarCacheSize {
+		c.packages = make(map[string][]SimilarPackage)
+	}
+	c.packages[id] = similar
+	return similar
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golangplus/strings"
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+// hitsDB is a database of hits whose text field has the tokens of the names
+// and synopses, and the imports fields the imports.
+type hitsDB struct {
+	database
+	hits []gcse.HitInfo
+}
+
+func (db hitsDB) PackageCount() int {
+	return len(db.hits)
+}
+
+func (db hitsDB) PackageOfDoc(docID int32) (gcse.HitInfo, bool) {
+	if docID < 0 || int(docID) >= len(db.hits) {
+		return gcse.HitInfo{}, false
+	}
+	return db.hits[docID], true
+}
+
+func (db hitsDB) FindFullPackage(id string) (gcse.HitInfo, bool) {
+	for _, hit := range db.hits {
+		if hit.Package == id {
+			return hit, true
+		}
+	}
+	return gcse.HitInfo{}, false
+}
+
+func (db hitsDB) hasToken(hit *gcse.HitInfo, field, token string) bool {
+	switch field {
+	case gcse.IndexTextField:
+		return gcse.AppendTokens(nil, []byte(hit.Name+" "+hit.Synopsis)).Contain(token)
+	case gcse.IndexPkgField:
+		return hit.Package == token
+	case gcse.IndexImportsField:
+		return stringsp.NewSet(hit.Imports...).Contain(token)
+	case gcse.IndexTestImportsField:
+		return stringsp.NewSet(hit.TestImports...).Contain(token)
+	}
+	return false
+}
+
+func (db hitsDB) TokenDocs(field, token string) []int32 {
+	var docs []int32
+	for i := range db.hits {
+		if db.hasToken(&db.hits[i], field, token) {
+			docs = append(docs, int32(i))
+		}
+	}
+	return docs
+}
+
+func (db hitsDB) PackageCountOfToken(field, token string) int {
+	cnt := 0
+	for i := range db.hits {
+		if db.hasToken(&db.hits[i], field, token) {
+			cnt++
+		}
+	}
+	return cnt
+}
+
+func (db hitsDB) Search(q map[string]stringsp.Set, out func(docID int32, data interface{}) error) error {
+	for i := range db.hits {
+		match := true
+		for field, tokens := range q {
+			for token := range tokens {
+				if !db.hasToken(&db.hits[i], field, token) {
+					match = false
+				}
+			}
+		}
+		if match {
+			if err := out(int32(i), db.hits[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestFindSimilarPackages(t *testing.T) {
+	newHit := func(pkg, name, synopsis string, imports []string) gcse.HitInfo {
+		return gcse.HitInfo{DocInfo: gcse.DocInfo{
+			Package:  pkg,
+			Name:     name,
+			Synopsis: synopsis,
+			Imports:  imports,
+		}}
+	}
+	db := hitsDB{hits: []gcse.HitInfo{
+		newHit("a/yaml", "yaml", "YAML support", []string{"a/reflect"}),
+		newHit("b/yaml", "yaml", "another YAML support", nil),
+		newHit("c/toml", "toml", "TOML parser", []string{"a/reflect"}),
+		newHit("d/conf", "conf", "config loader", nil),
+		newHit("a/app", "main", "an app", []string{"a/yaml", "d/conf"}),
+		newHit("a/reflect", "reflect", "reflection", nil),
+		newHit("e/misc", "misc", "nothing in common", nil),
+	}}
+	hit, _ := db.FindFullPackage("a/yaml")
+	similar := findSimilarPackages(db, &hit, 10)
+	var pkgs []string
+	for _, s := range similar {
+		pkgs = append(pkgs, s.Package)
+	}
+	// b/yaml shares two tokens, the others share an import or an importer.
+	assert.Equal(t, "pkgs", pkgs, []string{"b/yaml", "c/toml", "d/conf"})
+	assert.Equal(t, "similar[0].Synopsis", similar[0].Synopsis, "another YAML support")
+
+	assert.Equal(t, "len", len(findSimilarPackages(db, &hit, 1)), 1)
+}
+
+func TestSimilarCache(t *testing.T) {
+	var c similarCache
+	calls := 0
+	find := func() []SimilarPackage {
+		calls++
+		return []SimilarPackage{{Package: "b"}}
+	}
+	assert.Equal(t, "get", c.get("a", find), []SimilarPackage{{Package: "b"}})
+	assert.Equal(t, "get", c.get("a", find), []SimilarPackage{{Package: "b"}})
+	assert.Equal(t, "calls", calls, 1)
+}
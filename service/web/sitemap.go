@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/trace"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/gcse/configs"
+	"github.com/daviddengcn/go-easybi"
+)
+
+const (
+	sitemapPath      = "/sitemap.xml"
+	sitemapShardPath = "/sitemap/"
+
+	// The limit of URLs of a sitemap by the protocol.
+	sitemapShardSize = 50000
+)
+
+// siteMap is the sitemap of the view pages of the packages of an index,
+// sharded by docIDs into sitemaps listed by a sitemap index.
+type siteMap struct {
+	shardSize int
+	// The latest LastUpdated of the packages of each shard.
+	Lastmods []time.Time
+}
+
+func newSiteMap(packageCount, shardSize int) *siteMap {
+	return &siteMap{
+		shardSize: shardSize,
+		Lastmods:  make([]time.Time, (packageCount+shardSize-1)/shardSize),
+	}
+}
+
+// add updates the lastmod of the shard of docID with hit.
+func (m *siteMap) add(docID int32, hit *gcse.HitInfo) {
+	i := int(docID) / m.shardSize
+	if i < len(m.Lastmods) && hit.LastUpdated.After(m.Lastmods[i]) {
+		m.Lastmods[i] = hit.LastUpdated
+	}
+}
+
+// shardDocs returns the range of docIDs of shard i.
+func (m *siteMap) shardDocs(i int) (start, end int32) {
+	return int32(i * m.shardSize), int32((i + 1) * m.shardSize)
+}
+
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	Lastmod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name       `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapEntry `xml:"sitemap"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name       `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapEntry `xml:"url"`
+}
+
+func sitemapLastmod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func writeXML(w http.ResponseWriter, v interface{}) error {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
+
+// writeSitemapIndex writes the sitemap index of the shards of m. siteURL is
+// prefixed to the URLs.
+func writeSitemapIndex(w http.ResponseWriter, siteURL string, m *siteMap) error {
+	index := &sitemapIndex{}
+	for i, lastmod := range m.Lastmods {
+		index.Sitemaps = append(index.Sitemaps, sitemapEntry{
+			Loc:     siteURL + sitemapShardPath + strconv.Itoa(i) + ".xml",
+			Lastmod: sitemapLastmod(lastmod),
+		})
+	}
+	return writeXML(w, index)
+}
+
+// writeSitemapShard writes the sitemap of the view pages of the packages of
+// shard i of m.
+func writeSitemapShard(w http.ResponseWriter, siteURL string, db database, m *siteMap, i int) error {
+	start, end := m.shardDocs(i)
+	urls := &sitemapURLSet{}
+	for docID := start; docID < end; docID++ {
+		hit, ok := db.PackageOfDoc(docID)
+		if !ok {
+			continue
+		}
+		urls.URLs = append(urls.URLs, sitemapEntry{
+			Loc:     siteURL + "/view?id=" + url.QueryEscape(hit.Package),
+			Lastmod: sitemapLastmod(hit.LastUpdated),
+		})
+	}
+	return writeXML(w, urls)
+}
+
+// pageSitemap serves the sitemap index at /sitemap.xml and the sitemaps of
+// its shards at /sitemap/<n>.xml.
+func pageSitemap(w http.ResponseWriter, r *http.Request) {
+	tr := trace.New("pageSitemap", r.URL.Path)
+	defer tr.Finish()
+
+	db := getDatabase()
+	m := db.Sitemap()
+	if m == nil {
+		// Index not loaded.
+		m = newSiteMap(0, sitemapShardSize)
+	}
+	siteURL := configs.SiteURL
+	if r.URL.Path == sitemapPath {
+		bi.Inc("sitemap.index")
+		if err := writeSitemapIndex(w, siteURL, m); err != nil {
+			tr.LazyPrintf("writeSitemapIndex failed: %v", err)
+		}
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, sitemapShardPath)
+	i, err := strconv.Atoi(strings.TrimSuffix(name, ".xml"))
+	if !strings.HasSuffix(name, ".xml") || err != nil || i < 0 || i >= len(m.Lastmods) {
+		pageNotFound(w, r)
+		return
+	}
+	bi.Inc("sitemap.shard")
+	if err := writeSitemapShard(w, siteURL, db, m, i); err != nil {
+		tr.LazyPrintf("writeSitemapShard failed: %v", err)
+	}
+}
+
+// writeRobots writes the robots.txt of rules, with the sitemap index of the
+// site at siteURL.
+func writeRobots(w http.ResponseWriter, rules []byte, siteURL string) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := w.Write(rules); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "Sitemap: %s%s\n", siteURL, sitemapPath)
+	return err
+}
+
+// pageRobots serves static/robots.txt with the sitemap index of
+// configs.SiteURL.
+func pageRobots(w http.ResponseWriter, r *http.Request) {
+	rules, err := ioutil.ReadFile(configs.ServerRoot.Join("static", "robots.txt").S())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeRobots(w, rules, configs.SiteURL)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestSitemap(t *testing.T) {
+	tm := time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC)
+	var db hitsDB
+	for i, pkg := range []string{"a/x", "b/y", "c/z"} {
+		db.hits = append(db.hits, gcse.HitInfo{DocInfo: gcse.DocInfo{
+			Package:     pkg,
+			LastUpdated: tm.AddDate(0, 0, i),
+		}})
+	}
+	m := newSiteMap(len(db.hits), 2)
+	for i := range db.hits {
+		m.add(int32(i), &db.hits[i])
+	}
+	assert.Equal(t, "Lastmods", m.Lastmods, []time.Time{tm.AddDate(0, 0, 1), tm.AddDate(0, 0, 2)})
+
+	w := httptest.NewRecorder()
+	assert.NoError(t, writeSitemapIndex(w, "http://h", m))
+	var index sitemapIndex
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &index))
+	assert.Equal(t, "Sitemaps", index.Sitemaps, []sitemapEntry{
+		{Loc: "http://h/sitemap/0.xml", Lastmod: "2017-01-02T00:00:00Z"},
+		{Loc: "http://h/sitemap/1.xml", Lastmod: "2017-01-03T00:00:00Z"},
+	})
+
+	w = httptest.NewRecorder()
+	assert.NoError(t, writeSitemapShard(w, "http://h", db, m, 1))
+	var urls sitemapURLSet
+	assert.NoError(t, xml.Unmarshal(w.Body.Bytes(), &urls))
+	assert.Equal(t, "URLs", urls.URLs, []sitemapEntry{
+		{Loc: "http://h/view?id=c%2Fz", Lastmod: "2017-01-03T00:00:00Z"},
+	})
+}
+
+func TestWriteRobots(t *testing.T) {
+	w := httptest.NewRecorder()
+	assert.NoError(t, writeRobots(w, []byte("User-agent: *\nDisallow: /api\n"), "http://h"))
+	assert.Equal(t, "body", w.Body.String(), "User-agent: *\nDisallow: /api\nSitemap: http://h/sitemap.xml\n")
+}
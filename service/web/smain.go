@@ -63,18 +63,25 @@ func init() {
 	http.Handle("/js/", http.StripPrefix("/js/", http.FileServer(http.Dir(configs.ServerRoot.Join("js").S()))))
 	http.Handle("/images/", http.StripPrefix("/images/", http.FileServer(http.Dir(configs.ServerRoot.Join("images").S()))))
 	http.Handle("/img/", http.StripPrefix("/img/", http.FileServer(http.Dir(configs.ServerRoot.Join("images").S()))))
-	http.Handle("/robots.txt", http.FileServer(http.Dir(configs.ServerRoot.Join("static").S())))
+	http.HandleFunc("/robots.txt", pageRobots)
 
 	http.HandleFunc("/add", pageAdd)
-	http.HandleFunc("/search", pageSearch)
-	http.HandleFunc("/view", pageView)
-	http.HandleFunc("/tops", pageTops)
+	http.HandleFunc("/search", cachedByIndex(pageSearch))
+	http.HandleFunc("/view", cachedByIndex(pageView))
+	http.HandleFunc("/tops", cachedByIndex(pageTops))
 	http.HandleFunc("/about", staticPage("about.html"))
 	http.HandleFunc("/infoapi", staticPage("infoapi.html"))
-	http.HandleFunc("/api", pageApi)
+	http.HandleFunc("/api", cachedByIndex(pageApi))
+	http.HandleFunc(apiV2Path, cachedByIndex(pageApiV2))
+	http.HandleFunc(webhooksPath, pageWebhooks)
+	http.HandleFunc(feedPath, cachedByIndex(pageFeed))
+	http.HandleFunc(sitemapPath, cachedByIndex(pageSitemap))
+	http.HandleFunc(sitemapShardPath, cachedByIndex(pageSitemap))
+	http.HandleFunc(opensearchPath, cachedByIndex(pageOpensearch))
 	http.HandleFunc("/loadtemplates", pageLoadTemplate)
-	http.HandleFunc("/badge", pageBadge)
-	http.HandleFunc("/badgepage", pageBadgePage)
+	http.HandleFunc("/badge", cachedBadge(pageBadge))
+	http.HandleFunc("/badge.json", cachedBadge(pageBadgeJSON))
+	http.HandleFunc("/badgepage", cachedByIndex(pageBadgePage))
 	bi.HandleRequest(configs.BiWebPath)
 
 	http.HandleFunc("/", pageRoot)
@@ -217,6 +224,14 @@ func main() {
 	if err := configs.ImportSegments().ClearUndones(); err != nil {
 		log.Printf("CleanImportSegments failed: %v", err)
 	}
+	if configs.SynonymsPath != "" {
+		syns, err := loadSynonyms(configs.SynonymsPath)
+		if err != nil {
+			log.Fatalf("loadSynonyms %v failed: %v", configs.SynonymsPath, err)
+		}
+		querySynonyms = syns
+		log.Printf("%d synonyms loaded from %v", len(syns), configs.SynonymsPath)
+	}
 	if err := loadIndex(); err != nil {
 		log.Fatal(err)
 	}
@@ -225,6 +240,9 @@ func main() {
 
 	server := newServer()
 	http.HandleFunc("/crawlhistory", server.pageCrawlHistory)
+	if configs.SearchGrpcAddr != "" {
+		go serveSearchGrpc(configs.SearchGrpcAddr)
+	}
 
 	loadTemplates()
 
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/daviddengcn/gcse"
+)
+
+const (
+	minSpellWordLen = 3
+	// Longer words are usually identifiers or hashes rather than typos.
+	maxSpellWordLen = 20
+	// Words found in fewer packages are not suggested.
+	minSpellWordCount = 2
+)
+
+// spellChecker suggests corrections for misspelled query words. Each word of
+// the vocabulary is stored with all its single-rune deletions, so that words
+// within one edit of a query word, i.e. an insertion, a deletion, a
+// substitution or a transposition, are found by looking up the deletions of
+// the query word (the symmetric delete algorithm).
+type spellChecker struct {
+	counts  map[string]int // word -> number of packages
+	deletes map[string][]string
+}
+
+func newSpellChecker() *spellChecker {
+	return &spellChecker{
+		counts:  make(map[string]int),
+		deletes: make(map[string][]string),
+	}
+}
+
+// isSpellWord returns true if word is a candidate of spelling correction.
+func isSpellWord(word string) bool {
+	if len(word) < minSpellWordLen || len(word) > maxSpellWordLen {
+		return false
+	}
+	for _, r := range word {
+		if !unicode.IsLetter(r) || gcse.IsCJK(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// spellWords returns the lower-cased words of text which could be added to
+// the vocabulary.
+func spellWords(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	res := words[:0]
+	for _, w := range words {
+		if isSpellWord(w) {
+			res = append(res, w)
+		}
+	}
+	return res
+}
+
+func deletesOf(word string) []string {
+	runes := []rune(word)
+	dels := make([]string, 0, len(runes))
+	for i := range runes {
+		d := string(runes[:i]) + string(runes[i+1:])
+		if len(dels) > 0 && dels[len(dels)-1] == d {
+			// e.g. deleting either 'l' of "hello"
+			continue
+		}
+		dels = append(dels, d)
+	}
+	return dels
+}
+
+// addWord adds a vocabulary word found in count packages.
+func (sc *spellChecker) addWord(word string, count int) {
+	if _, ok := sc.counts[word]; ok {
+		return
+	}
+	sc.counts[word] = count
+	for _, d := range deletesOf(word) {
+		sc.deletes[d] = append(sc.deletes[d], word)
+	}
+}
+
+// editDistance returns the Damerau-Levenshtein distance (optimal string
+// alignment) between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			v := d[i-1][j] + 1
+			if d[i][j-1]+1 < v {
+				v = d[i][j-1] + 1
+			}
+			if d[i-1][j-1]+cost < v {
+				v = d[i-1][j-1] + cost
+			}
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] && d[i-2][j-2]+1 < v {
+				v = d[i-2][j-2] + 1
+			}
+			d[i][j] = v
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
+
+// Correct returns the vocabulary word within one edit of word found in the
+// most packages.
+func (sc *spellChecker) Correct(word string) (string, bool) {
+	if sc == nil || !isSpellWord(word) {
+		return "", false
+	}
+	word = strings.ToLower(word)
+	best, bestCount := "", 0
+	check := func(cand string) {
+		// Candidates sharing a deletion could be two edits away, e.g. "abc"
+		// and "bca".
+		if cand == word || editDistance(word, cand) != 1 {
+			return
+		}
+		cnt := sc.counts[cand]
+		if cnt > bestCount || cnt == bestCount && cand < best {
+			best, bestCount = cand, cnt
+		}
+	}
+	for _, v := range append([]string{word}, deletesOf(word)...) {
+		if _, ok := sc.counts[v]; ok {
+			check(v)
+		}
+		for _, cand := range sc.deletes[v] {
+			check(cand)
+		}
+	}
+	return best, best != ""
+}
+
+// correctQuery returns q with its unknown words replaced by the corrections
+// from db, or "" if nothing is corrected. Qualifiers, phrases and negated
+// words are kept as they are.
+func correctQuery(db database, q string) string {
+	terms := splitQuery(q)
+	corrected := false
+	for i, t := range terms {
+		if t.quoted || t.negated || !isSpellWord(t.text) {
+			continue
+		}
+		if db.PackageCountOfToken(gcse.IndexTextField, gcse.NormWord(t.text)) > 0 {
+			continue
+		}
+		if c, ok := db.CorrectWord(t.text); ok {
+			terms[i].text = c
+			corrected = true
+		}
+	}
+	if !corrected {
+		return ""
+	}
+	return joinQuery(terms)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, "websokcet", editDistance("websokcet", "websocket"), 1)
+	assert.Equal(t, "protobuff", editDistance("protobuff", "protobuf"), 1)
+	assert.Equal(t, "kitten", editDistance("kitten", "sitting"), 3)
+	assert.Equal(t, "same", editDistance("yaml", "yaml"), 0)
+}
+
+func TestSpellChecker_Correct(t *testing.T) {
+	sc := newSpellChecker()
+	sc.addWord("websocket", 100)
+	sc.addWord("protobuf", 200)
+	sc.addWord("protocol", 300)
+	sc.addWord("yaml", 50)
+	sc.addWord("toml", 40)
+
+	for _, c := range []struct {
+		word, exp string
+	}{
+		{"websokcet", "websocket"},
+		{"protobuff", "protobuf"},
+		{"Protobf", "protobuf"},
+		{"yamll", "yaml"},
+		{"xml", ""},
+		{"database", ""},
+		// Two edits.
+		{"protbff", ""},
+	} {
+		w, ok := sc.Correct(c.word)
+		assert.Equal(t, c.word, w, c.exp)
+		assert.Equal(t, c.word+" ok", ok, c.exp != "")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/golangplus/container/heap"
+	"github.com/golangplus/sort"
+)
+
+const (
+	defaultSuggestCount = 10
+	maxSuggestCount     = 50
+)
+
+type suggestEntry struct {
+	key   string // lower-cased text
+	text  string
+	score float64
+}
+
+// suggester completes prefixes with package names, import paths and popular
+// tokens. Entries are sorted by key so that the entries of a prefix form a
+// range, and a segment tree of the highest scored entry of each node finds the
+// top entries of a range without scanning it.
+type suggester struct {
+	entries []suggestEntry
+	// tree[i] is the index of the highest scored entry under node i. Leaves
+	// are at [size, 2*size).
+	tree []int32
+	size int
+}
+
+// suggestBuilder collects the entries of a suggester. An entry added more
+// than once keeps the highest score.
+type suggestBuilder map[string]suggestEntry
+
+func (b suggestBuilder) add(text string, score float64) {
+	if text == "" {
+		return
+	}
+	key := strings.ToLower(text)
+	if e, ok := b[key]; ok && e.score >= score {
+		return
+	}
+	b[key] = suggestEntry{key: key, text: text, score: score}
+}
+
+func (b suggestBuilder) build() *suggester {
+	s := &suggester{entries: make([]suggestEntry, 0, len(b))}
+	for _, e := range b {
+		s.entries = append(s.entries, e)
+	}
+	sortp.SortF(len(s.entries), func(i, j int) bool {
+		return s.entries[i].key < s.entries[j].key
+	}, func(i, j int) {
+		s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	})
+	s.size = 1
+	for s.size < len(s.entries) {
+		s.size *= 2
+	}
+	s.tree = make([]int32, 2*s.size)
+	for i := range s.tree[s.size:] {
+		s.tree[s.size+i] = int32(i)
+	}
+	for i := s.size - 1; i > 0; i-- {
+		s.tree[i] = s.better(s.tree[2*i], s.tree[2*i+1])
+	}
+	return s
+}
+
+// better returns the entry index with the higher score. Indexes out of the
+// entries are padding and never preferred.
+func (s *suggester) better(a, b int32) int32 {
+	if int(b) >= len(s.entries) {
+		return a
+	}
+	if int(a) >= len(s.entries) || s.entries[b].score > s.entries[a].score {
+		return b
+	}
+	return a
+}
+
+// best returns the index of the highest scored entry in [l, r), which must be
+// non-empty.
+func (s *suggester) best(l, r int) int32 {
+	res := int32(l)
+	for l, r = l+s.size, r+s.size; l < r; l, r = l/2, r/2 {
+		if l%2 == 1 {
+			res = s.better(res, s.tree[l])
+			l++
+		}
+		if r%2 == 1 {
+			r--
+			res = s.better(res, s.tree[r])
+		}
+	}
+	return res
+}
+
+// Suggest returns at most n texts starting with prefix, case-insensitively,
+// with higher scored ones first.
+func (s *suggester) Suggest(prefix string, n int) []string {
+	if s == nil || n <= 0 {
+		return nil
+	}
+	prefix = strings.ToLower(prefix)
+	lo := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].key >= prefix
+	})
+	hi := lo + sort.Search(len(s.entries)-lo, func(i int) bool {
+		return !strings.HasPrefix(s.entries[lo+i].key, prefix)
+	})
+	if lo >= hi {
+		return nil
+	}
+	// Each candidate is the best entry of a sub-range. Taking the best out
+	// splits its range into two, whose best entries become new candidates.
+	type candidate struct {
+		l, r int
+		idx  int32
+	}
+	cands := heap.NewInterfaces(func(a, b interface{}) bool {
+		return s.entries[a.(candidate).idx].score > s.entries[b.(candidate).idx].score
+	}, n)
+	cands.Push(candidate{l: lo, r: hi, idx: s.best(lo, hi)})
+	var res []string
+	for len(res) < n && cands.Len() > 0 {
+		c := cands.Pop().(candidate)
+		res = append(res, s.entries[c.idx].text)
+		if c.l < int(c.idx) {
+			cands.Push(candidate{l: c.l, r: int(c.idx), idx: s.best(c.l, int(c.idx))})
+		}
+		if int(c.idx)+1 < c.r {
+			cands.Push(candidate{l: int(c.idx) + 1, r: c.r, idx: s.best(int(c.idx)+1, c.r)})
+		}
+	}
+	return res
+}
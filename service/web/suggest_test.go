@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+)
+
+func TestSuggester_Suggest(t *testing.T) {
+	b := make(suggestBuilder)
+	b.add("gcse", 3)
+	b.add("github.com/daviddengcn/gcse", 3)
+	b.add("github.com/golang/glog", 5)
+	b.add("GLog", 1)
+	b.add("glog", 2) // overrides the score of "GLog"
+	b.add("gin", 4)
+	b.add("yaml", 10)
+	s := b.build()
+
+	assert.Equal(t, "g", s.Suggest("g", 10), []string{"github.com/golang/glog", "gin", "gcse", "github.com/daviddengcn/gcse", "glog"})
+	assert.Equal(t, "G 2", s.Suggest("G", 2), []string{"github.com/golang/glog", "gin"})
+	assert.Equal(t, "gl", s.Suggest("gl", 10), []string{"glog"})
+	assert.Equal(t, "github.com/", s.Suggest("github.com/", 10), []string{"github.com/golang/glog", "github.com/daviddengcn/gcse"})
+	assert.Equal(t, "x", s.Suggest("x", 10), []string(nil))
+	assert.Equal(t, "empty", s.Suggest("", 1), []string{"yaml"})
+
+	var nilS *suggester
+	assert.Equal(t, "nil", nilS.Suggest("g", 10), []string(nil))
+}
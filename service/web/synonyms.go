@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golangplus/strings"
+
+	"github.com/daviddengcn/gcse"
+)
+
+// synonymWeight is the weight of a synonym relative to the query token it
+// expands, applied to its idfs.
+const synonymWeight = 0.5
+
+// synonyms maps a lower-cased word to the other words of its groups.
+type synonyms map[string][]string
+
+// querySynonyms is loaded from configs.SynonymsPath on start.
+var querySynonyms synonyms
+
+// parseSynonyms reads synonym groups from r. Each line, unless empty or
+// starting with '#', is a group of words with the same meaning separated by
+// commas or spaces, e.g. "k8s, kubernetes".
+func parseSynonyms(r io.Reader) (synonyms, error) {
+	syns := make(synonyms)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var group []string
+		for _, w := range strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}) {
+			group = append(group, strings.ToLower(w))
+		}
+		for _, w := range group {
+			for _, syn := range group {
+				if syn != w && !containsString(syns[w], syn) {
+					syns[w] = append(syns[w], syn)
+				}
+			}
+		}
+	}
+	return syns, scanner.Err()
+}
+
+func containsString(l []string, s string) bool {
+	for _, e := range l {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func loadSynonyms(fn string) (synonyms, error) {
+	f, err := os.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseSynonyms(f)
+}
+
+// expansion is the synonym expansion of the plain words of a query. A word
+// with synonyms matches a package containing all tokens of the word or of any
+// of its synonyms.
+type expansion struct {
+	synonyms map[string][]string
+	words    []string // expanded words, sorted
+	// tokens of the expanded words and their synonyms
+	wordTokens map[string]stringsp.Set
+	// base are the text tokens of the query not from the expanded words.
+	base  stringsp.Set
+	added stringsp.Set
+	// docs maps the tokens of wordTokens to the sorted docIDs of the packages
+	// containing them in the text field.
+	docs map[string][]int32
+}
+
+// newExpansion returns nil if no plain word of q has synonyms.
+func newExpansion(db database, q *Query, syns synonyms) *expansion {
+	var e *expansion
+	var otherTokens stringsp.Set
+	for _, word := range q.Words {
+		word = strings.ToLower(word)
+		alts := syns[word]
+		if len(alts) == 0 {
+			otherTokens = gcse.AppendTokens(otherTokens, []byte(word))
+			continue
+		}
+		if e == nil {
+			e = &expansion{
+				synonyms:   make(map[string][]string),
+				wordTokens: make(map[string]stringsp.Set),
+				docs:       make(map[string][]int32),
+			}
+		}
+		if _, ok := e.synonyms[word]; ok {
+			continue
+		}
+		e.synonyms[word] = alts
+		e.words = append(e.words, word)
+		for _, w := range append([]string{word}, alts...) {
+			if _, ok := e.wordTokens[w]; ok {
+				continue
+			}
+			tokens := gcse.AppendTokens(nil, []byte(w))
+			e.wordTokens[w] = tokens
+			for token := range tokens {
+				if _, ok := e.docs[token]; ok {
+					continue
+				}
+				e.docs[token] = db.TokenDocs(gcse.IndexTextField, token)
+			}
+		}
+	}
+	if e == nil {
+		return nil
+	}
+	sort.Strings(e.words)
+	for token := range q.Text {
+		if _, ok := e.docs[token]; !ok || otherTokens.Contain(token) {
+			e.base.Add(token)
+		}
+	}
+	for token := range e.docs {
+		if !q.Text.Contain(token) {
+			e.added.Add(token)
+		}
+	}
+	return e
+}
+
+// Synonyms maps the expanded words to their synonyms.
+func (e *expansion) Synonyms() map[string][]string {
+	if e == nil {
+		return nil
+	}
+	return e.synonyms
+}
+
+// Added returns the tokens of the synonyms which are not in the query.
+func (e *expansion) Added() stringsp.Set {
+	if e == nil {
+		return nil
+	}
+	return e.added
+}
+
+// IsAdded returns true if token is from the synonyms and not in the query.
+func (e *expansion) IsAdded(token string) bool {
+	return e != nil && e.added.Contain(token)
+}
+
+// Contains returns false if token is from an expanded word or a synonym and
+// is not in the package of docID.
+func (e *expansion) Contains(docID int32, token string) bool {
+	if e == nil || e.base.Contain(token) {
+		return true
+	}
+	docs, ok := e.docs[token]
+	return !ok || containsDoc(docs, docID)
+}
+
+func (e *expansion) containsWord(docID int32, word string) bool {
+	for token := range e.wordTokens[word] {
+		if !containsDoc(e.docs[token], docID) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns true if the package of docID contains each expanded word or
+// any of its synonyms.
+func (e *expansion) Match(docID int32) bool {
+	if e == nil {
+		return true
+	}
+	for _, word := range e.words {
+		if e.containsWord(docID, word) {
+			continue
+		}
+		found := false
+		for _, syn := range e.synonyms[word] {
+			if e.containsWord(docID, syn) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchFields returns the queries passed to database.Search. The union of
+// their results, filtered by Match, is the hits of q.
+func (e *expansion) SearchFields(q *Query) []map[string]stringsp.Set {
+	if e == nil {
+		return []map[string]stringsp.Set{q.SearchFields()}
+	}
+	// Words other than the first one are checked by Match.
+	first := e.words[0]
+	var res []map[string]stringsp.Set
+	for _, w := range append([]string{first}, e.synonyms[first]...) {
+		var text stringsp.Set
+		text.Add(e.base.Elements()...)
+		text.Add(e.wordTokens[w].Elements()...)
+		fields := q.SearchFields()
+		fields[gcse.IndexTextField] = text
+		res = append(res, fields)
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golangplus/strings"
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+)
+
+func TestParseSynonyms(t *testing.T) {
+	syns, err := parseSynonyms(strings.NewReader(`
+# comment
+K8s, kubernetes
+db database
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "k8s", syns["k8s"], []string{"kubernetes"})
+	assert.Equal(t, "kubernetes", syns["kubernetes"], []string{"k8s"})
+	assert.Equal(t, "db", syns["db"], []string{"database"})
+	assert.Equal(t, "client", syns["client"], []string(nil))
+}
+
+type tokenDocsDB struct {
+	database
+	docs map[string][]int32
+}
+
+func (db tokenDocsDB) TokenDocs(field, token string) []int32 {
+	return db.docs[token]
+}
+
+func TestExpansion(t *testing.T) {
+	syns := synonyms{"k8s": {"kube"}, "kube": {"k8s"}}
+	db := tokenDocsDB{docs: make(map[string][]int32)}
+	// Packages 1 and 2 contain k8s, 2 and 3 contain kube, all contain client.
+	for token := range gcse.AppendTokens(nil, []byte("k8s")) {
+		db.docs[token] = []int32{1, 2}
+	}
+	db.docs["kube"] = []int32{2, 3}
+	db.docs["client"] = []int32{1, 2, 3, 4}
+
+	assert.Equal(t, "no synonyms", newExpansion(db, parseQuery("client"), syns) == nil, true)
+
+	q := parseQuery("K8s client")
+	e := newExpansion(db, q, syns)
+	assert.Equal(t, "Synonyms", e.Synonyms(), map[string][]string{"k8s": {"kube"}})
+	assert.Equal(t, "Added", e.Added(), stringsp.NewSet("kube"))
+	for docID, match := range []bool{false, true, true, true, false} {
+		assert.Equal(t, "Match", e.Match(int32(docID)), match)
+	}
+	assert.True(t, "Contains(1, k)", e.Contains(1, "k"))
+	assert.False(t, "Contains(3, k)", e.Contains(3, "k"))
+	assert.True(t, "Contains(4, client)", e.Contains(4, "client"))
+
+	assert.Equal(t, "SearchFields", e.SearchFields(q), []map[string]stringsp.Set{
+		{gcse.IndexTextField: gcse.AppendTokens(stringsp.NewSet("client"), []byte("k8s"))},
+		{gcse.IndexTextField: stringsp.NewSet("client", "kube")},
+	})
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/go-easybi"
+)
+
+const (
+	webhooksPath = apiV2Path + "webhooks"
+
+	maxWebhooks = 10000
+)
+
+// webhooksMu serializes the updates of the saved webhooks.
+var webhooksMu sync.Mutex
+
+func randomHex(n int) string {
+	bs := make([]byte, n)
+	rand.Read(bs)
+	return hex.EncodeToString(bs)
+}
+
+// newWebhook returns the webhook of the "url" parameter, which must be public,
+// subscribing one of the "package", "prefix" and "q" parameters, or an error
+// message.
+func newWebhook(r *http.Request) (*gcse.Webhook, string) {
+	wh := &gcse.Webhook{
+		URL:     strings.TrimSpace(r.FormValue("url")),
+		Package: strings.TrimSpace(r.FormValue("package")),
+		Prefix:  strings.TrimSpace(r.FormValue("prefix")),
+		Query:   strings.TrimSpace(r.FormValue("q")),
+	}
+	if err := gcse.CheckWebhookURL(wh.URL); err != nil {
+		return nil, fmt.Sprintf("Invalid url %s: %v", wh.URL, err)
+	}
+	cnt := 0
+	for _, v := range []string{wh.Package, wh.Prefix, wh.Query} {
+		if v != "" {
+			cnt++
+		}
+	}
+	if cnt != 1 {
+		return nil, "Exactly one of package, prefix and q is required"
+	}
+	if wh.Query != "" && len(gcse.AppendTokens(nil, []byte(wh.Query))) == 0 {
+		return nil, "No words in q: " + wh.Query
+	}
+	wh.ID, wh.Secret, wh.Created = randomHex(8), randomHex(16), time.Now()
+	return wh, ""
+}
+
+// pageWebhooks registers webhooks, which are called by the pipelines when
+// matching packages are added, updated, removed or change rank:
+//
+//	POST   /api/v2/webhooks?url=...&package|prefix|q=...   registers a webhook
+//	DELETE /api/v2/webhooks?id=...&secret=...              unregisters it
+//
+// The id and the secret signing the payloads are returned by registering.
+func pageWebhooks(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "POST":
+		bi.Inc("webhooks.register")
+		wh, msg := newWebhook(r)
+		if wh == nil {
+			apiV2Error(w, http.StatusBadRequest, "%s", msg)
+			return
+		}
+		webhooksMu.Lock()
+		defer webhooksMu.Unlock()
+		hooks, err := gcse.LoadWebhooks()
+		if err != nil {
+			apiV2Error(w, http.StatusInternalServerError, "Loading webhooks failed: %v", err)
+			return
+		}
+		if len(hooks) >= maxWebhooks {
+			apiV2Error(w, http.StatusServiceUnavailable, "Too many webhooks")
+			return
+		}
+		if err := gcse.SaveWebhooks(append(hooks, *wh)); err != nil {
+			apiV2Error(w, http.StatusInternalServerError, "Saving webhooks failed: %v", err)
+			return
+		}
+		apiV2Content(w, http.StatusCreated, wh)
+
+	case "DELETE":
+		bi.Inc("webhooks.unregister")
+		id, secret := r.FormValue("id"), r.FormValue("secret")
+		webhooksMu.Lock()
+		defer webhooksMu.Unlock()
+		hooks, err := gcse.LoadWebhooks()
+		if err != nil {
+			apiV2Error(w, http.StatusInternalServerError, "Loading webhooks failed: %v", err)
+			return
+		}
+		for i, wh := range hooks {
+			if wh.ID != id || subtle.ConstantTimeCompare([]byte(wh.Secret), []byte(secret)) != 1 {
+				continue
+			}
+			if err := gcse.SaveWebhooks(append(hooks[:i:i], hooks[i+1:]...)); err != nil {
+				apiV2Error(w, http.StatusInternalServerError, "Saving webhooks failed: %v", err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		apiV2Error(w, http.StatusNotFound, "Webhook %s not found", id)
+
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		apiV2Error(w, http.StatusMethodNotAllowed, "Method %s not allowed", r.Method)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/gcse/configs"
+)
+
+func TestPageWebhooks(t *testing.T) {
+	configs.SetTestingDataPath()
+
+	w := httptest.NewRecorder()
+	pageWebhooks(w, httptest.NewRequest("POST", "/api/v2/webhooks?url=ftp://h/x&package=a/b", nil))
+	assert.Equal(t, "code", w.Code, http.StatusBadRequest)
+	w = httptest.NewRecorder()
+	pageWebhooks(w, httptest.NewRequest("POST", "/api/v2/webhooks?url=http://203.0.113.1/x&package=a/b&prefix=a/", nil))
+	assert.Equal(t, "code", w.Code, http.StatusBadRequest)
+	w = httptest.NewRecorder()
+	pageWebhooks(w, httptest.NewRequest("POST", "/api/v2/webhooks?url=http://203.0.113.1/x&q=!!!", nil))
+	assert.Equal(t, "code", w.Code, http.StatusBadRequest)
+	for _, u := range []string{"http://127.0.0.1/x", "http://10.1.2.3/x", "http://169.254.169.254/x", "http://[::1]/x"} {
+		w = httptest.NewRecorder()
+		pageWebhooks(w, httptest.NewRequest("POST", "/api/v2/webhooks?url="+u+"&prefix=a/", nil))
+		assert.Equal(t, "code of "+u, w.Code, http.StatusBadRequest)
+	}
+
+	w = httptest.NewRecorder()
+	pageWebhooks(w, httptest.NewRequest("POST", "/api/v2/webhooks?url=http://203.0.113.1/x&prefix=a/", nil))
+	assert.Equal(t, "code", w.Code, http.StatusCreated)
+	var wh gcse.Webhook
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &wh))
+	assert.Equal(t, "Prefix", wh.Prefix, "a/")
+	hooks, err := gcse.LoadWebhooks()
+	assert.NoError(t, err)
+	assert.Equal(t, "len(hooks)", len(hooks), 1)
+
+	w = httptest.NewRecorder()
+	pageWebhooks(w, httptest.NewRequest("DELETE", "/api/v2/webhooks?id="+wh.ID+"&secret=wrong", nil))
+	assert.Equal(t, "code", w.Code, http.StatusNotFound)
+	w = httptest.NewRecorder()
+	pageWebhooks(w, httptest.NewRequest("DELETE", "/api/v2/webhooks?id="+wh.ID+"&secret="+wh.Secret, nil))
+	assert.Equal(t, "code", w.Code, http.StatusNoContent)
+	hooks, err = gcse.LoadWebhooks()
+	assert.NoError(t, err)
+	assert.Equal(t, "len(hooks)", len(hooks), 0)
+}
@@ -8,6 +8,7 @@ It is generated from these files:
 	github.com/daviddengcn/gcse/shared/proto/spider.proto
 	github.com/daviddengcn/gcse/shared/proto/store.proto
 	github.com/daviddengcn/gcse/shared/proto/stored.proto
+	github.com/daviddengcn/gcse/shared/proto/search.proto
 
 It has these top-level messages:
 	GoFileInfo
@@ -22,6 +23,17 @@ It has these top-level messages:
 	Repository
 	PackageCrawlHistoryReq
 	PackageCrawlHistoryResp
+	SearchReq
+	SearchHit
+	SearchResp
+	IndexedPackage
+	GetPackageReq
+	GetPackageResp
+	ListImportersReq
+	ListImportersResp
+	TopsReq
+	TopList
+	TopsResp
 */
 package gcsepb
 
@@ -112,7 +112,7 @@ func repoInfoFromGithub(repo *github.Repository) *gpb.RepoInfo {
 	ri.CrawlingTime, _ = ptypes.TimestampProto(time.Now())
 	ri.LastUpdated, _ = ptypes.TimestampProto(getTimestamp(repo.PushedAt).Time)
 	if repo.Source != nil {
-		ri.Source = stringsp.Get(repo.Source.Name)
+		ri.Source = "github.com/" + stringsp.Get(repo.Source.FullName)
 	}
 	return ri
 }
@@ -52,6 +52,31 @@ func ReadPackageHistoryOf(box *bh.RefCountBox, site, path string) (*gpb.HistoryI
 	return readHistoryOf(box, pkgsRoot, site, path)
 }
 
+// ForEachPackageHistoryOf calls f with the history of every package in box,
+// in one transaction.
+func ForEachPackageHistoryOf(box *bh.RefCountBox, f func(site, path string, info *gpb.HistoryInfo) error) error {
+	return box.View(func(tx bh.Tx) error {
+		return tx.ForEach([][]byte{historyRoot, pkgsRoot}, func(b bh.Bucket, site, v bytesp.Slice) error {
+			if v != nil {
+				log.Printf("Unexpected value %q for key %q, ignored", string(v), string(site))
+				return nil
+			}
+			return b.ForEach([][]byte{site}, func(path, bs bytesp.Slice) error {
+				if bs == nil {
+					log.Printf("Unexpected nil value for key %q, ignored", string(path))
+					return nil
+				}
+				info := &gpb.HistoryInfo{}
+				if err := errorsp.WithStacksAndMessage(proto.Unmarshal(bs, info), "Unmarshal %d bytes", len(bs)); err != nil {
+					log.Printf("Unmarshaling value for %v failed, ignored: %v", path, err)
+					return nil
+				}
+				return errorsp.WithStacks(f(string(site), string(path), info))
+			})
+		})
+	})
+}
+
 func ReadPersonHistory(site, path string) (*gpb.HistoryInfo, error) {
 	return readHistory(personsRoot, site, path)
 }
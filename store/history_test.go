@@ -86,6 +86,28 @@ func TestAppendPackageEvent(t *testing.T) {
 	})
 }
 
+func TestForEachPackageHistoryOf(t *testing.T) {
+	const (
+		site     = "TestForEachPackageHistoryOf.com"
+		path     = "gcse"
+		foundWay = "testing"
+	)
+	assert.NoError(t, UpdatePackageHistory(site, path, func(info *gpb.HistoryInfo) error {
+		info.FoundWay = foundWay
+		return nil
+	}))
+	defer DeletePackageHistory(site, path)
+
+	var found *gpb.HistoryInfo
+	assert.NoError(t, ForEachPackageHistoryOf(box, func(s, p string, info *gpb.HistoryInfo) error {
+		if s == site && p == path {
+			found = info
+		}
+		return nil
+	}))
+	assert.Equal(t, "found", found, &gpb.HistoryInfo{FoundWay: foundWay})
+}
+
 func TestUpdateReadDeletePersonHistory(t *testing.T) {
 	const (
 		site     = "TestUpdateReadDeletePersonHistory.com"
@@ -1,6 +1,7 @@
 package gcse
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/golangplus/strings"
@@ -20,3 +21,40 @@ func TestTokenize2(t *testing.T) {
 	assert.Equal(t, "tokens", tokens,
 		stringsp.NewSet("pub", "sub", "hub", "pubsub", "subhub", "pubsubhub"))
 }
+
+func TestNewFieldStats(t *testing.T) {
+	stats := NewFieldStats("yaml json", "yaml")
+	assert.Equal(t, "stats.Len", stats.Len, 3)
+	assert.Equal(t, "stats.Freqs", stats.Freqs, map[string]int{"yaml": 2})
+	assert.Equal(t, "Freq(yaml)", stats.Freq("yaml"), 2)
+	assert.Equal(t, "Freq(json)", stats.Freq("json"), 1)
+
+	stats = NewFieldStats("yaml yaml", strings.Repeat("json ", 5), strings.Repeat("toml ", 70))
+	assert.Equal(t, "FreqTokens", stats.FreqTokens(), stringsp.NewSet("yaml#2", "json#4", "toml#64"))
+}
+
+func TestAppendRawTokens(t *testing.T) {
+	text := []byte("Parsing parsers")
+	assert.Equal(t, "tokens", AppendRawTokens(nil, text), stringsp.NewSet("parsing", "parsers"))
+	assert.Equal(t, "stemmed", AppendTokens(nil, text), stringsp.NewSet(NormWord("parsing"), NormWord("parsers")))
+}
+
+func TestAppendSymbolTokens(t *testing.T) {
+	var tokens stringsp.Set
+	for _, sym := range []string{"NewRouter", "Router.ServeHTTP", ""} {
+		tokens = AppendSymbolTokens(tokens, sym)
+	}
+	assert.Equal(t, "tokens", tokens, stringsp.NewSet("NewRouter", "Router.ServeHTTP", "ServeHTTP"))
+}
+
+func TestTokenize_CJK(t *testing.T) {
+	text := []byte("Go语言 café，東京")
+	tokens := AppendTokens(nil, text)
+	assert.Equal(t, "tokens", tokens,
+		stringsp.NewSet("go", "语", "言", "语言", NormWord("café"), "東", "京", "東京"))
+
+	assert.True(t, "IsCJK(语)", IsCJK('语'))
+	assert.True(t, "IsCJK(テ)", IsCJK('テ'))
+	assert.True(t, "IsCJK(한)", IsCJK('한'))
+	assert.False(t, "IsCJK(é)", IsCJK('é'))
+}
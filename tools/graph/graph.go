@@ -0,0 +1,90 @@
+// Command graph exports the import graph of the docs DB in Graphviz DOT,
+// GraphML or node/edge JSON to stdout.
+//
+//	graph [-format dot|graphml|json] [-tests] [-projects] [-pkg <path> | -project <project>]
+//
+// The whole corpus is exported if neither -pkg nor -project is set.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/daviddengcn/gcse"
+	"github.com/daviddengcn/gcse/configs"
+	"github.com/daviddengcn/sophie"
+	"github.com/daviddengcn/sophie/kv"
+)
+
+type imports struct {
+	imports, testImports []string
+}
+
+// forEachDoc calls f with every doc in the docs DB.
+func forEachDoc(f func(pkg string, doc *gcse.DocInfo)) {
+	kvDir := kv.DirInput(configs.DocsDBFsPath())
+	cnt, err := kvDir.PartCount()
+	if err != nil {
+		log.Fatalf("kvDir.PartCount() failed: %v", err)
+	}
+	var key sophie.RawString
+	var val gcse.DocInfo
+	for part := 0; part < cnt; part++ {
+		it, err := kvDir.Iterator(part)
+		if err != nil {
+			log.Fatalf("kvDir.Iterator(%d) failed: %v", part, err)
+		}
+		for {
+			if err := it.Next(&key, &val); err != nil {
+				if err == sophie.EOF {
+					break
+				}
+				log.Fatalf("it.Next failed %v", err)
+			}
+			f(key.String(), &val)
+		}
+		it.Close()
+	}
+}
+
+func main() {
+	format := flag.String("format", gcse.GraphFormatDOT, "output format: dot, graphml or json")
+	pkg := flag.String("pkg", "", "export the imports of the package, directly or not")
+	project := flag.String("project", "", "export the imports of the packages of the project, directly or not")
+	tests := flag.Bool("tests", false, "include test imports")
+	projects := flag.Bool("projects", false, "collapse packages to their projects")
+	flag.Parse()
+
+	g := &gcse.ImportGraph{Tests: *tests, Projects: *projects}
+	if *pkg == "" && *project == "" {
+		forEachDoc(func(pkg string, doc *gcse.DocInfo) {
+			g.AddPackage(pkg, doc.Imports, doc.TestImports)
+		})
+	} else {
+		all := make(map[string]imports)
+		var roots []string
+		forEachDoc(func(p string, doc *gcse.DocInfo) {
+			all[p] = imports{doc.Imports, doc.TestImports}
+			if p == *pkg || *pkg == "" && gcse.FullProjectOfPackage(p) == *project {
+				roots = append(roots, p)
+			}
+		})
+		if len(roots) == 0 {
+			log.Fatalf("No packages found for -pkg %q -project %q", *pkg, *project)
+		}
+		g.AddImportClosure(roots, func(p string) ([]string, []string, bool) {
+			imps, found := all[p]
+			return imps.imports, imps.testImports, found
+		})
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := gcse.WriteImportGraph(w, g, *format); err != nil {
+		log.Fatalf("WriteImportGraph failed: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatalf("Flush failed: %v", err)
+	}
+}
@@ -0,0 +1,324 @@
+package gcse
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/golangplus/errors"
+
+	"github.com/daviddengcn/gcse/configs"
+	"github.com/daviddengcn/gcse/utils"
+)
+
+// Types of WebhookEvent.
+const (
+	WebhookAdded   = "added"
+	WebhookUpdated = "updated"
+	WebhookRemoved = "removed"
+	WebhookRank    = "rank"
+)
+
+const (
+	// The header of the signature of a webhook payload, "sha256=" followed by
+	// the hex HMAC-SHA256 of the body keyed by the secret of the webhook.
+	WebhookSignatureHeader = "X-Gcse-Signature"
+	// More events of a webhook in a run are dropped.
+	maxWebhookEvents = 1000
+	// The number of webhooks posted to at the same time, and the deadline of
+	// posting to all of them.
+	maxWebhookSenders  = 8
+	webhookSendTimeout = 5 * time.Minute
+	// A rank change is notified if it is by more than this ratio of the old
+	// rank, or crosses one of webhookRankBoundaries.
+	webhookRankChangeRatio = 0.2
+)
+
+// The ranks of the tops, e.g. 100 for the top 100, whose crossings are
+// notified.
+var webhookRankBoundaries = []int{10, 100, 1000, 10000}
+
+// webhookIPAllowed returns whether webhooks may be posted to ip. Replaced in
+// tests posting to local servers.
+var webhookIPAllowed = isPublicIP
+
+// isPublicIP returns false for loopback, private, link-local and unspecified
+// addresses, so that webhooks cannot reach the internal network.
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsInterfaceLocalMulticast() && !ip.IsUnspecified()
+}
+
+// checkWebhookDial rejects connections to addresses not allowed, which
+// CheckWebhookURL cannot catch if the host resolves differently when posting.
+func checkWebhookDial(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !webhookIPAllowed(ip) {
+		return fmt.Errorf("address %s not allowed", address)
+	}
+	return nil
+}
+
+var webhookClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: checkWebhookDial,
+		}).DialContext,
+	},
+}
+
+// CheckWebhookURL returns an error if u is not an http(s) URL, or its host
+// does not resolve to only public addresses.
+func CheckWebhookURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return fmt.Errorf("not an http(s) URL: %s", u)
+	}
+	ips, err := net.LookupIP(parsed.Hostname())
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if !webhookIPAllowed(ip) {
+			return fmt.Errorf("address %v of %s not allowed", ip, parsed.Hostname())
+		}
+	}
+	return nil
+}
+
+// IsNotableRankChange returns whether the change of a 1-based static rank
+// from old to new is notified to webhooks.
+func IsNotableRankChange(old, new int) bool {
+	for _, b := range webhookRankBoundaries {
+		if (old <= b) != (new <= b) {
+			return true
+		}
+	}
+	return math.Abs(float64(new-old)) > webhookRankChangeRatio*float64(old)
+}
+
+// Webhook is a subscription to the changes of the packages matching one of
+// Package, Prefix and Query.
+type Webhook struct {
+	ID     string `json:"id"`
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+	// The import path of the package.
+	Package string `json:"package,omitempty"`
+	// The prefix of the import paths.
+	Prefix string `json:"prefix,omitempty"`
+	// Packages whose name, import path and synopsis contain all the tokens of
+	// the query.
+	Query   string    `json:"query,omitempty"`
+	Created time.Time `json:"created"`
+}
+
+// Matches returns whether the package of doc is subscribed by wh.
+func (wh *Webhook) Matches(doc *DocInfo) bool {
+	switch {
+	case wh.Package != "":
+		return doc.Package == wh.Package
+	case wh.Prefix != "":
+		return strings.HasPrefix(doc.Package, wh.Prefix)
+	case wh.Query != "":
+		query := AppendTokens(nil, []byte(wh.Query))
+		if len(query) == 0 {
+			// Matches nothing rather than everything.
+			return false
+		}
+		tokens := AppendTokens(nil, []byte(doc.Name+" "+doc.Package+" "+doc.Synopsis))
+		for token := range query {
+			if !tokens.Contain(token) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// WebhookEvent is a change of a package sent to webhooks.
+type WebhookEvent struct {
+	Type     string `json:"type"`
+	Package  string `json:"package"`
+	Name     string `json:"name,omitempty"`
+	Synopsis string `json:"synopsis,omitempty"`
+	// The 1-based static ranks before and after a WebhookRank change.
+	OldRank int `json:"old_rank,omitempty"`
+	NewRank int `json:"new_rank,omitempty"`
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook.
+type WebhookPayload struct {
+	Webhook string         `json:"webhook"`
+	Time    time.Time      `json:"time"`
+	Events  []WebhookEvent `json:"events"`
+}
+
+// LoadWebhooks reads the webhooks saved at configs.WebhooksPath(). Returns
+// nil if there are none.
+func LoadWebhooks() ([]Webhook, error) {
+	var hooks []Webhook
+	if err := utils.ReadJsonFile(configs.WebhooksPath(), &hooks); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, errorsp.WithStacks(err)
+	}
+	return hooks, nil
+}
+
+// SaveWebhooks replaces the webhooks saved at configs.WebhooksPath(). The file
+// is renamed into place so readers never see a partial one.
+func SaveWebhooks(hooks []Webhook) error {
+	fn := configs.WebhooksPath()
+	if err := utils.WriteJsonFile(fn+".tmp", hooks); err != nil {
+		return errorsp.WithStacks(err)
+	}
+	return errorsp.WithStacks(os.Rename(fn+".tmp", fn))
+}
+
+// SignWebhookPayload returns the value of WebhookSignatureHeader of body.
+func SignWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookNotifier collects the events of the packages matching webhooks, and
+// sends them in one payload per webhook. It is safe for concurrent use.
+type WebhookNotifier struct {
+	hooks []Webhook
+
+	mu     sync.Mutex
+	events map[string][]WebhookEvent
+}
+
+func NewWebhookNotifier(hooks []Webhook) *WebhookNotifier {
+	return &WebhookNotifier{
+		hooks:  hooks,
+		events: make(map[string][]WebhookEvent),
+	}
+}
+
+// Matches returns whether the package of doc is subscribed by any webhook.
+func (n *WebhookNotifier) Matches(doc *DocInfo) bool {
+	for i := range n.hooks {
+		if n.hooks[i].Matches(doc) {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds e, of the package of doc, to the webhooks matching it. The
+// package, name and synopsis of e are set from doc.
+func (n *WebhookNotifier) Add(doc *DocInfo, e WebhookEvent) {
+	var ids []string
+	for i := range n.hooks {
+		if n.hooks[i].Matches(doc) {
+			ids = append(ids, n.hooks[i].ID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	e.Package, e.Name, e.Synopsis = doc.Package, doc.Name, doc.Synopsis
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, id := range ids {
+		if len(n.events[id]) < maxWebhookEvents {
+			n.events[id] = append(n.events[id], e)
+		}
+	}
+}
+
+// Send POSTs the events collected to the webhooks, at most maxWebhookSenders
+// at a time and within webhookSendTimeout. Failures are logged and not
+// retried.
+func (n *WebhookNotifier) Send() {
+	n.mu.Lock()
+	events := n.events
+	n.events = make(map[string][]WebhookEvent)
+	n.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookSendTimeout)
+	defer cancel()
+	senders := make(chan struct{}, maxWebhookSenders)
+	var wg sync.WaitGroup
+	for i := range n.hooks {
+		wh := &n.hooks[i]
+		events := events[wh.ID]
+		if len(events) == 0 {
+			continue
+		}
+		select {
+		case senders <- struct{}{}:
+		case <-ctx.Done():
+			log.Printf("Posting %d events to webhook %s skipped: %v", len(events), wh.ID, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-senders }()
+			if err := postWebhook(ctx, wh, &WebhookPayload{
+				Webhook: wh.ID,
+				Time:    time.Now(),
+				Events:  events,
+			}); err != nil {
+				log.Printf("Posting %d events to webhook %s failed: %v", len(events), wh.ID, err)
+				return
+			}
+			log.Printf("%d events posted to webhook %s", len(events), wh.ID)
+		}()
+	}
+	wg.Wait()
+}
+
+func postWebhook(ctx context.Context, wh *Webhook, payload *WebhookPayload) error {
+	if err := CheckWebhookURL(wh.URL); err != nil {
+		return errorsp.WithStacks(err)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errorsp.WithStacks(err)
+	}
+	req, err := http.NewRequest("POST", wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return errorsp.WithStacks(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(wh.Secret, body))
+	resp, err := webhookClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return errorsp.WithStacks(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
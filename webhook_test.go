@@ -0,0 +1,72 @@
+package gcse
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golangplus/testing/assert"
+)
+
+func TestWebhook_Matches(t *testing.T) {
+	doc := &DocInfo{Package: "github.com/a/raft", Name: "raft", Synopsis: "Raft consensus"}
+	assert.True(t, "package", (&Webhook{Package: "github.com/a/raft"}).Matches(doc))
+	assert.False(t, "package", (&Webhook{Package: "github.com/a"}).Matches(doc))
+	assert.True(t, "prefix", (&Webhook{Prefix: "github.com/a/"}).Matches(doc))
+	assert.False(t, "prefix", (&Webhook{Prefix: "github.com/b/"}).Matches(doc))
+	assert.True(t, "query", (&Webhook{Query: "Raft consensus"}).Matches(doc))
+	assert.False(t, "query", (&Webhook{Query: "raft paxos"}).Matches(doc))
+	assert.False(t, "query", (&Webhook{Query: "!!!"}).Matches(doc))
+	assert.False(t, "empty", (&Webhook{}).Matches(doc))
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	assert.NoError(t, CheckWebhookURL("https://203.0.113.1/hook"))
+	for _, u := range []string{
+		"ftp://203.0.113.1/hook",
+		"http:///hook",
+		"http://127.0.0.1/hook",
+		"http://192.168.1.1:8080/hook",
+		"http://169.254.169.254/hook",
+		"http://0.0.0.0/hook",
+		"http://[fe80::1]/hook",
+	} {
+		assert.True(t, u, CheckWebhookURL(u) != nil)
+	}
+}
+
+func TestIsNotableRankChange(t *testing.T) {
+	assert.True(t, "2 -> 1", IsNotableRankChange(2, 1))
+	assert.True(t, "105 -> 95", IsNotableRankChange(105, 95))
+	assert.True(t, "500 -> 700", IsNotableRankChange(500, 700))
+	assert.False(t, "500 -> 520", IsNotableRankChange(500, 520))
+	assert.False(t, "5000 -> 4500", IsNotableRankChange(5000, 4500))
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var payload WebhookPayload
+	var signature, expSignature string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		signature, expSignature = r.Header.Get(WebhookSignatureHeader), SignWebhookPayload("s", body)
+		json.Unmarshal(body, &payload)
+	}))
+	defer ts.Close()
+	defer func(allowed func(net.IP) bool) { webhookIPAllowed = allowed }(webhookIPAllowed)
+	webhookIPAllowed = func(net.IP) bool { return true }
+
+	n := NewWebhookNotifier([]Webhook{{ID: "h", URL: ts.URL, Secret: "s", Prefix: "github.com/a/"}})
+	n.Add(&DocInfo{Package: "github.com/a/x", Name: "x"}, WebhookEvent{Type: WebhookAdded})
+	n.Add(&DocInfo{Package: "github.com/b/y"}, WebhookEvent{Type: WebhookRemoved})
+	n.Add(&DocInfo{Package: "github.com/a/z"}, WebhookEvent{Type: WebhookRank, OldRank: 2, NewRank: 1})
+	n.Send()
+	assert.Equal(t, "signature", signature, expSignature)
+	assert.Equal(t, "Webhook", payload.Webhook, "h")
+	assert.Equal(t, "Events", payload.Events, []WebhookEvent{
+		{Type: WebhookAdded, Package: "github.com/a/x", Name: "x"},
+		{Type: WebhookRank, Package: "github.com/a/z", OldRank: 2, NewRank: 1},
+	})
+}